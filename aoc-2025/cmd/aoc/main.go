@@ -4,16 +4,38 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"strings"
 
 	"aoc-2025/internal/registry"
 	_ "aoc-2025/internal/solutions" // Ensure solutions are registered
 	"aoc-2025/internal/util"
 )
 
+// paramFlags collects repeated -param flags, each of the form name=value.
+type paramFlags []string
+
+func (p *paramFlags) String() string {
+	return strings.Join(*p, ",")
+}
+
+func (p *paramFlags) Set(value string) error {
+	if !strings.Contains(value, "=") {
+		return fmt.Errorf("expected name=value, got %q", value)
+	}
+	*p = append(*p, value)
+	return nil
+}
+
 func main() {
 	day := flag.Int("day", 0, "day number (1-12)")
 	part := flag.Int("part", 0, "part number (1 or 2)")
 	inputPath := flag.String("input", "", "custom input file path")
+	verbose := flag.Bool("verbose", false, "print diagnostic output alongside the answer")
+	exportPath := flag.String("export", "", "file to export intermediate results to (format chosen by extension)")
+	renderPath := flag.String("render", "", "file to render the solution to as an SVG image")
+	variant := flag.String("variant", "", "alternative solver implementation to use (e.g. reference)")
+	var params paramFlags
+	flag.Var(&params, "param", "solver parameter as name=value (repeatable)")
 	flag.Parse()
 
 	if *day < 1 || *day > 12 {
@@ -28,6 +50,26 @@ func main() {
 		log.Fatalf("no solver registered for day %d part %d", *day, *part)
 	}
 
+	for _, p := range params {
+		name, value, _ := strings.Cut(p, "=")
+		if err := registry.SetParam(*day, *part, name, value); err != nil {
+			log.Fatalf("invalid parameter: %v", err)
+		}
+	}
+
+	registry.SetVerbose(*verbose)
+	registry.SetVariant(*variant)
+	registry.SetExportPath(*exportPath)
+	registry.SetRenderPath(*renderPath)
+
+	// Prefer reading the input as a stream when the solver supports it
+	if streamSolver := registry.LookupStream(*day, *part); streamSolver != nil {
+		f := util.OpenInput(*day, *inputPath)
+		defer f.Close()
+		fmt.Println(streamSolver(f))
+		return
+	}
+
 	input := util.LoadInput(*day, *inputPath)
 	fmt.Println(solver(input))
 }
@@ -0,0 +1,201 @@
+package automaton
+
+import "fmt"
+
+// Neighbourhood is the set of [row, col] offsets from a cell to each of its neighbours.
+type Neighbourhood [][2]int
+
+// Moore returns the neighbourhood of every cell within the given Chebyshev distance, which for
+// a radius of 1 is the 8 cells surrounding a cell.
+func Moore(radius int) Neighbourhood {
+	return offsetsWithin(radius, func(dr, dc int) bool { return true })
+}
+
+// VonNeumann returns the neighbourhood of every cell within the given Manhattan distance, which
+// for a radius of 1 is the 4 cells orthogonally adjacent to a cell.
+func VonNeumann(radius int) Neighbourhood {
+	return offsetsWithin(radius, func(dr, dc int) bool { return abs(dr)+abs(dc) <= radius })
+}
+
+// ParseNeighbourhood returns the named neighbourhood ("moore" or "vonneumann") of the given radius.
+func ParseNeighbourhood(name string, radius int) (Neighbourhood, error) {
+	if radius < 1 {
+		return nil, fmt.Errorf("radius must be positive, got %d", radius)
+	}
+
+	switch name {
+	case "moore":
+		return Moore(radius), nil
+	case "vonneumann":
+		return VonNeumann(radius), nil
+	}
+	return nil, fmt.Errorf("unknown neighbourhood %q (expected moore or vonneumann)", name)
+}
+
+// offsetsWithin returns the offsets in the square of the given radius, other than the cell itself,
+// that satisfy include, in row-major order.
+func offsetsWithin(radius int, include func(dr, dc int) bool) Neighbourhood {
+	var offsets Neighbourhood
+	for dr := -radius; dr <= radius; dr++ {
+		for dc := -radius; dc <= radius; dc++ {
+			if (dr != 0 || dc != 0) && include(dr, dc) {
+				offsets = append(offsets, [2]int{dr, dc})
+			}
+		}
+	}
+	return offsets
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// Rule decides whether a cell is alive in the next generation from whether it is alive now
+// and how many of its neighbours are.
+type Rule func(alive bool, liveNeighbours int) bool
+
+// Automaton is a two-dimensional grid of cells that are either alive or dead, which evolves by
+// applying a rule to every cell at once. The number of live neighbours of each cell is maintained
+// as cells change, so that the cost of a generation depends on how many cells change.
+type Automaton struct {
+	rows, cols     int
+	alive          []bool
+	liveNeighbours []int
+	neighbourhood  Neighbourhood
+	wrap           bool
+}
+
+// New creates an automaton from a rectangular grid of live cells. If wrap is true, the grid is
+// a torus, with neighbours beyond one edge taken from the opposite edge; otherwise cells beyond
+// the edges are dead. On a torus smaller than the neighbourhood, a cell reached through several
+// offsets is counted once for each of them. It returns an error if the rows differ in length.
+func New(grid [][]bool, neighbourhood Neighbourhood, wrap bool) (*Automaton, error) {
+	a := &Automaton{rows: len(grid), neighbourhood: neighbourhood, wrap: wrap}
+	if a.rows > 0 {
+		a.cols = len(grid[0])
+	}
+	for r, row := range grid {
+		if len(row) != a.cols {
+			return nil, fmt.Errorf("grid is not rectangular: row %d has %d cells, but row 1 has %d",
+				r+1, len(row), a.cols)
+		}
+	}
+	a.alive = make([]bool, a.rows*a.cols)
+	a.liveNeighbours = make([]int, a.rows*a.cols)
+
+	for r, row := range grid {
+		for c, alive := range row {
+			if alive {
+				a.set(r, c, true)
+			}
+		}
+	}
+	return a, nil
+}
+
+// Size returns the number of rows and columns in the grid.
+func (a *Automaton) Size() (int, int) {
+	return a.rows, a.cols
+}
+
+// Alive reports whether the cell is alive.
+func (a *Automaton) Alive(row, col int) bool {
+	return a.alive[row*a.cols+col]
+}
+
+// LiveNeighbours returns the number of live cells in the cell's neighbourhood.
+func (a *Automaton) LiveNeighbours(row, col int) int {
+	return a.liveNeighbours[row*a.cols+col]
+}
+
+// Neighbours calls visit with every cell in the cell's neighbourhood that lies on the grid.
+func (a *Automaton) Neighbours(row, col int, visit func(row, col int)) {
+	for _, offset := range a.neighbourhood {
+		r, c := row+offset[0], col+offset[1]
+		if a.wrap {
+			r, c = ((r%a.rows)+a.rows)%a.rows, ((c%a.cols)+a.cols)%a.cols
+		} else if r < 0 || r >= a.rows || c < 0 || c >= a.cols {
+			continue
+		}
+		visit(r, c)
+	}
+}
+
+// set changes whether the cell is alive, updating its neighbours' counts of live neighbours.
+func (a *Automaton) set(row, col int, alive bool) {
+	if a.alive[row*a.cols+col] == alive {
+		return
+	}
+	a.alive[row*a.cols+col] = alive
+
+	delta := 1
+	if !alive {
+		delta = -1
+	}
+	a.Neighbours(row, col, func(r, c int) {
+		a.liveNeighbours[r*a.cols+c] += delta
+	})
+}
+
+// Step advances the automaton by one generation, applying the rule to every cell at once,
+// and returns the [row, col] locations of the cells that changed.
+func (a *Automaton) Step(rule Rule) [][2]int {
+	var changed [][2]int
+	for r := 0; r < a.rows; r++ {
+		for c := 0; c < a.cols; c++ {
+			i := r*a.cols + c
+			if rule(a.alive[i], a.liveNeighbours[i]) != a.alive[i] {
+				changed = append(changed, [2]int{r, c})
+			}
+		}
+	}
+
+	for _, loc := range changed {
+		a.set(loc[0], loc[1], !a.Alive(loc[0], loc[1]))
+	}
+	return changed
+}
+
+// Erode repeatedly kills every live cell that does not survive with its current number of live
+// neighbours, one generation at a time, until every remaining cell survives. It returns the
+// [row, col] locations of the cells killed in each generation. Survival must not become possible
+// by losing neighbours, so that only the neighbours of cells killed in one generation need to be
+// re-examined for the next, rather than the whole grid.
+func (a *Automaton) Erode(survives func(liveNeighbours int) bool) [][][2]int {
+	dying := make([]bool, a.rows*a.cols) // Cells already due to be killed
+
+	var generation [][2]int
+	for i, alive := range a.alive {
+		if alive && !survives(a.liveNeighbours[i]) {
+			generation = append(generation, [2]int{i / a.cols, i % a.cols})
+			dying[i] = true
+		}
+	}
+
+	var generations [][][2]int
+	for len(generation) > 0 {
+		generations = append(generations, generation)
+
+		// Cells in a generation die simultaneously, so kill them all before examining neighbours
+		for _, loc := range generation {
+			a.set(loc[0], loc[1], false)
+		}
+
+		var next [][2]int
+		for _, loc := range generation {
+			a.Neighbours(loc[0], loc[1], func(r, c int) {
+				i := r*a.cols + c
+				if a.alive[i] && !dying[i] && !survives(a.liveNeighbours[i]) {
+					next = append(next, [2]int{r, c})
+					dying[i] = true
+				}
+			})
+		}
+		generation = next
+	}
+
+	return generations
+}
@@ -0,0 +1,191 @@
+package checked
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// ErrOverflow is returned when a result does not fit in the numeric type being used.
+var ErrOverflow = errors.New("integer overflow")
+
+// ErrDivisionByZero is returned when dividing by zero.
+var ErrDivisionByZero = errors.New("division by zero")
+
+// ErrNegativeExponent is returned when raising to a negative power, whose result is not an integer.
+var ErrNegativeExponent = errors.New("negative exponent")
+
+// ErrExponentTooLarge is returned when raising to a power whose result would take too long to compute.
+var ErrExponentTooLarge = errors.New("exponent too large")
+
+// maxPowBits bounds the size in bits of arbitrary-precision powers, around 315,000 decimal digits.
+const maxPowBits = 1 << 20
+
+// Arithmetic provides the operations that counting code needs over the numeric type T,
+// so that the same code can run on fixed-width integers or arbitrary-precision ones.
+type Arithmetic[T any] interface {
+	Zero() T
+	FromInt(n int) T
+	Add(a, b T) (T, error)
+	Sub(a, b T) (T, error)
+	Mul(a, b T) (T, error)
+	Div(a, b T) (T, error) // Truncates towards zero
+	Pow(a, b T) (T, error)
+	Cmp(a, b T) int // Returns -1, 0 or +1 as a is less than, equal to or greater than b
+}
+
+// Int64 is overflow-checked arithmetic on int64 values, returning ErrOverflow
+// instead of silently wrapping around.
+type Int64 struct{}
+
+func (Int64) Zero() int64 {
+	return 0
+}
+
+func (Int64) FromInt(n int) int64 {
+	return int64(n)
+}
+
+func (Int64) Add(a, b int64) (int64, error) {
+	if (b > 0 && a > math.MaxInt64-b) || (b < 0 && a < math.MinInt64-b) {
+		return 0, fmt.Errorf("%w: %d + %d", ErrOverflow, a, b)
+	}
+	return a + b, nil
+}
+
+func (Int64) Sub(a, b int64) (int64, error) {
+	if (b < 0 && a > math.MaxInt64+b) || (b > 0 && a < math.MinInt64+b) {
+		return 0, fmt.Errorf("%w: %d - %d", ErrOverflow, a, b)
+	}
+	return a - b, nil
+}
+
+func (Int64) Mul(a, b int64) (int64, error) {
+	if a == 0 || b == 0 {
+		return 0, nil
+	}
+	product := a * b
+	if product/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return 0, fmt.Errorf("%w: %d * %d", ErrOverflow, a, b)
+	}
+	return product, nil
+}
+
+func (Int64) Div(a, b int64) (int64, error) {
+	if b == 0 {
+		return 0, fmt.Errorf("%w: %d / %d", ErrDivisionByZero, a, b)
+	}
+	if a == math.MinInt64 && b == -1 {
+		return 0, fmt.Errorf("%w: %d / %d", ErrOverflow, a, b)
+	}
+	return a / b, nil
+}
+
+func (arith Int64) Pow(a, b int64) (int64, error) {
+	if b < 0 {
+		return 0, fmt.Errorf("%w: %d ^ %d", ErrNegativeExponent, a, b)
+	}
+
+	// Exponentiation by squaring, only squaring the base while bits of the exponent remain
+	result, base, exp := int64(1), a, b
+	var err error
+	for exp > 0 {
+		if exp&1 == 1 {
+			if result, err = arith.Mul(result, base); err != nil {
+				return 0, fmt.Errorf("%w: %d ^ %d", ErrOverflow, a, b)
+			}
+		}
+		if exp >>= 1; exp > 0 {
+			if base, err = arith.Mul(base, base); err != nil {
+				return 0, fmt.Errorf("%w: %d ^ %d", ErrOverflow, a, b)
+			}
+		}
+	}
+	return result, nil
+}
+
+func (Int64) Cmp(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// Big is arbitrary-precision arithmetic on *big.Int values, which never overflows, although
+// Pow returns ErrExponentTooLarge rather than computing results of more than maxPowBits bits.
+// Operations always allocate a new result and never modify their operands.
+type Big struct{}
+
+func (Big) Zero() *big.Int {
+	return new(big.Int)
+}
+
+func (Big) FromInt(n int) *big.Int {
+	return big.NewInt(int64(n))
+}
+
+func (Big) Add(a, b *big.Int) (*big.Int, error) {
+	return new(big.Int).Add(a, b), nil
+}
+
+func (Big) Sub(a, b *big.Int) (*big.Int, error) {
+	return new(big.Int).Sub(a, b), nil
+}
+
+func (Big) Mul(a, b *big.Int) (*big.Int, error) {
+	return new(big.Int).Mul(a, b), nil
+}
+
+func (Big) Div(a, b *big.Int) (*big.Int, error) {
+	if b.Sign() == 0 {
+		return nil, fmt.Errorf("%w: %s / %s", ErrDivisionByZero, a, b)
+	}
+	return new(big.Int).Quo(a, b), nil
+}
+
+func (Big) Pow(a, b *big.Int) (*big.Int, error) {
+	if b.Sign() < 0 {
+		return nil, fmt.Errorf("%w: %s ^ %s", ErrNegativeExponent, a, b)
+	}
+
+	// Powers of 0, 1 and -1 stay small however large the exponent. Any other base adds at least
+	// one bit, and at least its own bit length less one, to the result with every factor.
+	if a.CmpAbs(big.NewInt(1)) <= 0 {
+		if b.Sign() == 0 || (a.Sign() < 0 && b.Bit(0) == 0) {
+			return big.NewInt(1), nil
+		}
+		return new(big.Int).Set(a), nil
+	}
+	if !b.IsInt64() || b.Int64() > maxPowBits ||
+		(b.Sign() > 0 && int64(a.BitLen()-1) > maxPowBits/b.Int64()) {
+		return nil, fmt.Errorf("%w: %s ^ %s", ErrExponentTooLarge, a, b)
+	}
+	return new(big.Int).Exp(a, b, nil), nil
+}
+
+func (Big) Cmp(a, b *big.Int) int {
+	return a.Cmp(b)
+}
+
+// WithFallback runs a computation with overflow-checked int64 arithmetic and, if that overflows,
+// runs it again with arbitrary precision. The result is formatted in decimal. Errors other than
+// overflow are returned as they are.
+func WithFallback(compute func(Int64) (int64, error), computeBig func(Big) (*big.Int, error)) (string, error) {
+	result, err := compute(Int64{})
+	if err == nil {
+		return fmt.Sprint(result), nil
+	}
+	if !errors.Is(err, ErrOverflow) {
+		return "", err
+	}
+
+	bigResult, err := computeBig(Big{})
+	if err != nil {
+		return "", err
+	}
+	return bigResult.String(), nil
+}
@@ -0,0 +1,44 @@
+package checked
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestBigPow(t *testing.T) {
+	tests := []struct {
+		a, b    int64
+		want    string
+		wantErr error
+	}{
+		{2, 10, "1024", nil},
+		{-3, 3, "-27", nil},
+		{7, 0, "1", nil},
+		{0, 0, "1", nil},
+		{0, 387420489, "0", nil},
+		{1, 387420489, "1", nil},
+		{-1, 387420489, "-1", nil},
+		{-1, 387420488, "1", nil},
+		{2, maxPowBits, "", nil},
+		{2, maxPowBits + 1, "", ErrExponentTooLarge},
+		{9, 387420489, "", ErrExponentTooLarge},
+		{2, -1, "", ErrNegativeExponent},
+	}
+	for _, tt := range tests {
+		got, err := Big{}.Pow(big.NewInt(tt.a), big.NewInt(tt.b))
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("Pow(%d, %d) error = %v, want %v", tt.a, tt.b, err, tt.wantErr)
+			continue
+		}
+		if err == nil && tt.want != "" && got.String() != tt.want {
+			t.Errorf("Pow(%d, %d) = %s, want %s", tt.a, tt.b, got, tt.want)
+		}
+	}
+
+	// An exponent that does not even fit in an int64 is rejected rather than attempted
+	huge := new(big.Int).Lsh(big.NewInt(1), 100)
+	if _, err := (Big{}).Pow(big.NewInt(3), huge); !errors.Is(err, ErrExponentTooLarge) {
+		t.Errorf("Pow(3, 2^100) error = %v, want %v", err, ErrExponentTooLarge)
+	}
+}
@@ -0,0 +1,133 @@
+package polygontest
+
+import (
+	"aoc-2025/internal/polygon"
+	"math/rand"
+	"slices"
+)
+
+// Random generates the vertices of a random rectilinear polygon with coordinates in [0, size),
+// shaped like a histogram: a run of columns, each spanning a random range of y values that
+// overlaps its neighbours. Columns may be a single unit wide and neighbouring edges a single unit
+// apart, so vertex coordinates are often adjacent. The polygon is transposed half the time.
+func Random(rng *rand.Rand, size int) [][2]int {
+	numColumns := 1 + rng.Intn(min(6, size-1))
+	xs := rng.Perm(size)[:numColumns+1]
+	slices.Sort(xs)
+
+	// Every column spans the middle of the range, so that neighbouring columns always overlap
+	var vertices [][2]int
+	add := func(x, y int) {
+		if n := len(vertices); n == 0 || vertices[n-1] != [2]int{x, y} {
+			vertices = append(vertices, [2]int{x, y})
+		}
+	}
+	bottoms := make([]int, numColumns)
+	for i := range numColumns {
+		top := size/2 + rng.Intn(size-size/2)
+		bottoms[i] = rng.Intn(size / 2)
+		add(xs[i], top)
+		add(xs[i+1], top)
+	}
+	for i := numColumns - 1; i >= 0; i-- {
+		add(xs[i+1], bottoms[i])
+		add(xs[i], bottoms[i])
+	}
+	if vertices[0] == vertices[len(vertices)-1] {
+		vertices = vertices[:len(vertices)-1]
+	}
+
+	if rng.Intn(2) == 0 {
+		for i := range vertices {
+			vertices[i][0], vertices[i][1] = vertices[i][1], vertices[i][0]
+		}
+	}
+	return vertices
+}
+
+// Raster is a polygon drawn on a grid at twice the resolution of its coordinates, so that the
+// points halfway between vertex coordinates, where neighbouring edges may leave a gap, are kept.
+// It is built without ray casting, as an independent check on the polygon package.
+type Raster struct {
+	minX, minY int
+	inside     [][]bool // inside[y][x] for doubled coordinates offset from the minimum
+}
+
+// Rasterize draws the boundary of the polygon with the given vertices, then flood fills the grid
+// from a margin around it. Every point not reached is inside the polygon or on its boundary.
+func Rasterize(vertices [][2]int) *Raster {
+	minX, minY, maxX, maxY := vertices[0][0], vertices[0][1], vertices[0][0], vertices[0][1]
+	for _, v := range vertices {
+		minX, minY, maxX, maxY = min(minX, v[0]), min(minY, v[1]), max(maxX, v[0]), max(maxY, v[1])
+	}
+
+	// Leave a margin of one doubled unit on every side, which the flood fill starts from
+	r := &Raster{minX: minX - 1, minY: minY - 1}
+	width, height := 2*(maxX-minX)+5, 2*(maxY-minY)+5
+	boundary := make([][]bool, height)
+	for y := range boundary {
+		boundary[y] = make([]bool, width)
+	}
+	for i, start := range vertices {
+		end := vertices[(i+1)%len(vertices)]
+		x1, y1 := 2*(start[0]-r.minX), 2*(start[1]-r.minY)
+		x2, y2 := 2*(end[0]-r.minX), 2*(end[1]-r.minY)
+		for y := min(y1, y2); y <= max(y1, y2); y++ {
+			for x := min(x1, x2); x <= max(x1, x2); x++ {
+				boundary[y][x] = true
+			}
+		}
+	}
+
+	outside := make([][]bool, height)
+	for y := range outside {
+		outside[y] = make([]bool, width)
+	}
+	outside[0][0] = true
+	stack := [][2]int{{0, 0}}
+	for len(stack) > 0 {
+		x, y := stack[len(stack)-1][0], stack[len(stack)-1][1]
+		stack = stack[:len(stack)-1]
+		for _, dir := range [4][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+			nx, ny := x+dir[0], y+dir[1]
+			if nx >= 0 && nx < width && ny >= 0 && ny < height && !boundary[ny][nx] && !outside[ny][nx] {
+				outside[ny][nx] = true
+				stack = append(stack, [2]int{nx, ny})
+			}
+		}
+	}
+
+	r.inside = make([][]bool, height)
+	for y := range r.inside {
+		r.inside[y] = make([]bool, width)
+		for x := range r.inside[y] {
+			r.inside[y][x] = !outside[y][x]
+		}
+	}
+	return r
+}
+
+// Contains reports whether the point lies inside the polygon or on its boundary.
+func (r *Raster) Contains(pt polygon.Point) bool {
+	return r.containsDoubled(2*(pt.X-r.minX), 2*(pt.Y-r.minY))
+}
+
+// containsDoubled reports whether the point at the given doubled grid position is inside,
+// treating every position off the grid as outside.
+func (r *Raster) containsDoubled(x, y int) bool {
+	return y >= 0 && y < len(r.inside) && x >= 0 && x < len(r.inside[y]) && r.inside[y][x]
+}
+
+// ContainsRectangle reports whether every point of the rectangle at the raster's resolution is
+// inside the polygon or on its boundary, which for a polygon with integer vertices means the
+// whole rectangle is.
+func (r *Raster) ContainsRectangle(rect polygon.Rectangle) bool {
+	for y := 2 * (rect.MinY - r.minY); y <= 2*(rect.MaxY-r.minY); y++ {
+		for x := 2 * (rect.MinX - r.minX); x <= 2*(rect.MaxX-r.minX); x++ {
+			if !r.containsDoubled(x, y) {
+				return false
+			}
+		}
+	}
+	return true
+}
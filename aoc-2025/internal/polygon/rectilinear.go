@@ -0,0 +1,216 @@
+package polygon
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Point is a vertex or query point on the integer grid.
+type Point struct {
+	X, Y int
+}
+
+// Rectangle is an axis-aligned rectangle given by its inclusive bounds.
+type Rectangle struct {
+	MinX, MinY, MaxX, MaxY int
+}
+
+// Orientation is the winding direction of a polygon's vertices.
+type Orientation int
+
+const (
+	Clockwise Orientation = iota
+	CounterClockwise
+)
+
+func (o Orientation) String() string {
+	if o == Clockwise {
+		return "clockwise"
+	}
+	return "counterclockwise"
+}
+
+// Rectilinear is a simple polygon whose edges are all axis-aligned, given by its vertices in
+// order. The last vertex connects back to the first. Points on the boundary count as inside.
+type Rectilinear struct {
+	vertices []Point
+}
+
+// NewRectilinear builds a rectilinear polygon from its vertices as [x, y] pairs, checking that
+// every pair of consecutive vertices (wrapping around) shares exactly one coordinate.
+func NewRectilinear(vertices [][2]int) (*Rectilinear, error) {
+	if len(vertices) < 4 {
+		return nil, fmt.Errorf("rectilinear polygon needs at least 4 vertices, got %d", len(vertices))
+	}
+
+	p := &Rectilinear{vertices: make([]Point, len(vertices))}
+	for i, v := range vertices {
+		p.vertices[i] = Point{v[0], v[1]}
+	}
+	for i, start := range p.vertices {
+		end := p.vertices[(i+1)%len(p.vertices)]
+		if (start.X == end.X) == (start.Y == end.Y) {
+			return nil, fmt.Errorf("edge from %v to %v is not axis-aligned", start, end)
+		}
+	}
+	if p.doubledSignedArea() == 0 {
+		return nil, errors.New("polygon encloses no area")
+	}
+
+	return p, nil
+}
+
+// Vertices returns the polygon's vertices in order.
+func (p *Rectilinear) Vertices() []Point {
+	return p.vertices
+}
+
+// edges calls visit for every edge of the polygon, stopping early if visit returns false.
+func (p *Rectilinear) edges(visit func(start, end Point) bool) {
+	for i, start := range p.vertices {
+		if !visit(start, p.vertices[(i+1)%len(p.vertices)]) {
+			return
+		}
+	}
+}
+
+// doubledSignedArea computes twice the signed area enclosed by the polygon using the shoelace
+// formula. It is positive when the vertices wind counterclockwise with the y-axis pointing up.
+func (p *Rectilinear) doubledSignedArea() int {
+	area := 0
+	p.edges(func(start, end Point) bool {
+		area += start.X*end.Y - end.X*start.Y
+		return true
+	})
+	return area
+}
+
+// Orientation detects whether the vertices wind clockwise or counterclockwise, taking the
+// y-axis to point up. With the y-axis pointing down, as in screen coordinates, the result flips.
+func (p *Rectilinear) Orientation() Orientation {
+	if p.doubledSignedArea() > 0 {
+		return CounterClockwise
+	}
+	return Clockwise
+}
+
+// Contains reports whether the point lies inside the polygon or on its boundary.
+func (p *Rectilinear) Contains(pt Point) bool {
+	return p.containsDoubled(2*pt.X, 2*pt.Y)
+}
+
+// containsDoubled reports whether the point (x/2, y/2) lies inside the polygon or on its boundary.
+// Working in doubled coordinates keeps points halfway between grid lines exact.
+func (p *Rectilinear) containsDoubled(x, y int) bool {
+	inside := false
+	onBoundary := false
+	p.edges(func(start, end Point) bool {
+		x1, y1, x2, y2 := 2*start.X, 2*start.Y, 2*end.X, 2*end.Y
+		minX, maxX, minY, maxY := min(x1, x2), max(x1, x2), min(y1, y2), max(y1, y2)
+		if x >= minX && x <= maxX && y >= minY && y <= maxY {
+			onBoundary = true
+			return false
+		}
+
+		// Cast a ray towards positive x and count the vertical edges it crosses. Treating each
+		// edge as half-open in y ensures a ray through a vertex is counted exactly once.
+		if x1 == x2 && x < x1 && y >= minY && y < maxY {
+			inside = !inside
+		}
+		return true
+	})
+
+	return onBoundary || inside
+}
+
+// ContainsRectangle reports whether the rectangle lies entirely inside the polygon, boundary
+// included. No polygon edge may pass through the rectangle's interior, which leaves the interior
+// either wholly inside or wholly outside the polygon, so the rectangle's centre must also be inside.
+// A rectangle with zero width or height has no interior and is checked as a segment instead.
+func (p *Rectilinear) ContainsRectangle(rect Rectangle) bool {
+	if rect.MinX == rect.MaxX || rect.MinY == rect.MaxY {
+		return p.containsSegment(rect)
+	}
+
+	crossed := false
+	p.edges(func(start, end Point) bool {
+		crossed = isSegmentCrossingRectangle(start, end, rect)
+		return !crossed
+	})
+	if crossed {
+		return false
+	}
+
+	return p.containsDoubled(rect.MinX+rect.MaxX, rect.MinY+rect.MaxY)
+}
+
+// containsSegment reports whether a rectangle with zero width or height, which is a horizontal
+// or vertical segment (or a single point), lies entirely inside the polygon. Containment along
+// the segment can only change at vertex coordinates, so it suffices to check the endpoints, every
+// vertex coordinate strictly between them, and the midpoints between consecutive such stops.
+func (p *Rectilinear) containsSegment(rect Rectangle) bool {
+	horizontal := rect.MinX < rect.MaxX
+	lo, hi := rect.MinY, rect.MaxY
+	if horizontal {
+		lo, hi = rect.MinX, rect.MaxX
+	}
+
+	stops := []int{lo, hi}
+	for _, v := range p.vertices {
+		c := v.Y
+		if horizontal {
+			c = v.X
+		}
+		if c > lo && c < hi {
+			stops = append(stops, c)
+		}
+	}
+	slices.Sort(stops)
+	stops = slices.Compact(stops)
+
+	// Check each stop and the midpoint after it, in doubled coordinates along the segment
+	for i, stop := range stops {
+		along := []int{2 * stop}
+		if i+1 < len(stops) {
+			along = append(along, stop+stops[i+1])
+		}
+		for _, a := range along {
+			x, y := 2*rect.MinX, a
+			if horizontal {
+				x, y = a, 2*rect.MinY
+			}
+			if !p.containsDoubled(x, y) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// isSegmentCrossingRectangle checks if an axis-aligned line segment crosses
+// through the interior of a rectangle.
+func isSegmentCrossingRectangle(start, end Point, rect Rectangle) bool {
+	// Terminate early if both segment endpoints are outside rectangle bounds
+	if (start.X < rect.MinX && end.X < rect.MinX) || (start.X > rect.MaxX && end.X > rect.MaxX) ||
+		(start.Y < rect.MinY && end.Y < rect.MinY) || (start.Y > rect.MaxY && end.Y > rect.MaxY) {
+		return false
+	}
+
+	if start.X == end.X {
+		// Vertical segment is problematic if strictly inside rectangle's x-range
+		if start.X > rect.MinX && start.X < rect.MaxX {
+			minSeg, maxSeg := min(start.Y, end.Y), max(start.Y, end.Y)
+			return minSeg < rect.MaxY && maxSeg > rect.MinY
+		}
+	} else {
+		// Horizontal segment is problematic if strictly inside rectangle's y-range
+		if start.Y > rect.MinY && start.Y < rect.MaxY {
+			minSeg, maxSeg := min(start.X, end.X), max(start.X, end.X)
+			return minSeg < rect.MaxX && maxSeg > rect.MinX
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,99 @@
+package polygon_test
+
+import (
+	"aoc-2025/internal/polygon"
+	"aoc-2025/internal/polygon/polygontest"
+	"math/rand"
+	"testing"
+)
+
+// Coordinates of random polygons lie in [0, randomPolygonSize)
+const randomPolygonSize = 24
+
+func TestContainsRectangleDegenerate(t *testing.T) {
+	p, err := polygon.NewRectilinear([][2]int{{0, 0}, {0, 2}, {1, 2}, {1, 1}, {4, 1}, {4, 2}, {7, 2}, {7, 0}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		rect polygon.Rectangle
+		want bool
+	}{
+		{polygon.Rectangle{1, 2, 7, 2}, false}, // Runs along the top, across the notch between x = 1 and 4
+		{polygon.Rectangle{4, 2, 7, 2}, true},  // Runs along the top edge only
+		{polygon.Rectangle{0, 1, 7, 1}, true},  // Runs along the bottom of the notch
+		{polygon.Rectangle{2, 0, 2, 2}, false}, // Rises out through the notch
+		{polygon.Rectangle{2, 0, 2, 1}, true},
+		{polygon.Rectangle{2, 2, 2, 2}, false}, // Single point inside the notch
+		{polygon.Rectangle{1, 2, 1, 2}, true},  // Single point on a vertex
+	}
+	for _, tt := range tests {
+		if got := p.ContainsRectangle(tt.rect); got != tt.want {
+			t.Errorf("ContainsRectangle(%v) = %v, want %v", tt.rect, got, tt.want)
+		}
+	}
+}
+
+// newPolygonAndRaster builds the polygon with the given vertices along with its rasterization.
+func newPolygonAndRaster(t *testing.T, vertices [][2]int) (*polygon.Rectilinear, *polygontest.Raster) {
+	t.Helper()
+	p, err := polygon.NewRectilinear(vertices)
+	if err != nil {
+		t.Fatalf("NewRectilinear(%v): %v", vertices, err)
+	}
+	return p, polygontest.Rasterize(vertices)
+}
+
+// checkAgainstRaster compares Contains and ContainsRectangle with the rasterized polygon, for
+// the given corners taken modulo a range just wider than the polygon's.
+func checkAgainstRaster(t *testing.T, p *polygon.Rectilinear, raster *polygontest.Raster, x1, y1, x2, y2 int) {
+	t.Helper()
+	vertices := p.Vertices()
+	// Map every corner into [-1, randomPolygonSize], leaving those already in range untouched
+	const span = randomPolygonSize + 2
+	wrap := func(n int) int { return ((n+1)%span+span)%span - 1 }
+	x1, y1, x2, y2 = wrap(x1), wrap(y1), wrap(x2), wrap(y2)
+	rect := polygon.Rectangle{MinX: min(x1, x2), MinY: min(y1, y2), MaxX: max(x1, x2), MaxY: max(y1, y2)}
+
+	if pt := (polygon.Point{X: x1, Y: y1}); p.Contains(pt) != raster.Contains(pt) {
+		t.Fatalf("polygon %v: Contains(%v) = %v, want %v", vertices, pt, p.Contains(pt), raster.Contains(pt))
+	}
+	if got, want := p.ContainsRectangle(rect), raster.ContainsRectangle(rect); got != want {
+		t.Fatalf("polygon %v: ContainsRectangle(%v) = %v, want %v", vertices, rect, got, want)
+	}
+}
+
+func TestContainsRectangleMatchesRaster(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for range 300 {
+		vertices := polygontest.Random(rng, randomPolygonSize)
+		p, raster := newPolygonAndRaster(t, vertices)
+		for range 200 {
+			x1, y1, x2, y2 := rng.Int(), rng.Int(), rng.Int(), rng.Int()
+			// Half the time, corner the rectangle on vertices as in day 9, so more of them land inside
+			if rng.Intn(2) == 0 {
+				a, b := vertices[rng.Intn(len(vertices))], vertices[rng.Intn(len(vertices))]
+				x1, y1, x2, y2 = a[0], a[1], b[0], b[1]
+			}
+			// Make a third of the rectangles a single row or column of tiles
+			switch rng.Intn(3) {
+			case 0:
+				x2 = x1
+			case 1:
+				y2 = y1
+			}
+			checkAgainstRaster(t, p, raster, x1, y1, x2, y2)
+		}
+	}
+}
+
+func FuzzContainsRectangle(f *testing.F) {
+	f.Add(int64(1), 1, 2, 7, 2)
+	f.Add(int64(2), 0, 0, 0, 23)
+	f.Add(int64(3), 5, 5, 12, 12)
+	f.Fuzz(func(t *testing.T, seed int64, x1, y1, x2, y2 int) {
+		p, raster := newPolygonAndRaster(t, polygontest.Random(rand.New(rand.NewSource(seed)), randomPolygonSize))
+		checkAgainstRaster(t, p, raster, x1, y1, x2, y2)
+	})
+}
@@ -0,0 +1,248 @@
+package polyomino
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Polyomino is a shape made of grid cells, each given as a [row, col] pair.
+type Polyomino [][2]int
+
+// Key is a hashable representation of a normalized polyomino. Two polyominoes have
+// the same key exactly when they cover the same cells after normalization.
+type Key string
+
+// Symmetry is an element of the dihedral group D4, the eight symmetries of a square.
+type Symmetry int
+
+const (
+	Identity       Symmetry = iota
+	Rotate90                // Clockwise quarter turn
+	Rotate180               // Half turn
+	Rotate270               // Counterclockwise quarter turn
+	FlipHorizontal          // Mirror across the vertical axis
+	FlipVertical            // Mirror across the horizontal axis
+	Transpose               // Mirror across the main diagonal
+	AntiTranspose           // Mirror across the anti-diagonal
+)
+
+// D4 lists every symmetry of the square, starting with the identity.
+var D4 = [8]Symmetry{Identity, Rotate90, Rotate180, Rotate270, FlipHorizontal, FlipVertical, Transpose, AntiTranspose}
+
+// matrices holds the linear map of each symmetry acting on [row, col] column vectors.
+var matrices = [8][2][2]int{
+	Identity:       {{1, 0}, {0, 1}},
+	Rotate90:       {{0, 1}, {-1, 0}},
+	Rotate180:      {{-1, 0}, {0, -1}},
+	Rotate270:      {{0, -1}, {1, 0}},
+	FlipHorizontal: {{1, 0}, {0, -1}},
+	FlipVertical:   {{-1, 0}, {0, 1}},
+	Transpose:      {{0, 1}, {1, 0}},
+	AntiTranspose:  {{0, -1}, {-1, 0}},
+}
+
+var symmetryNames = [8]string{
+	"identity", "rotate 90", "rotate 180", "rotate 270",
+	"flip horizontal", "flip vertical", "transpose", "anti-transpose",
+}
+
+func (s Symmetry) String() string {
+	if s < 0 || int(s) >= len(symmetryNames) {
+		return fmt.Sprintf("Symmetry(%d)", int(s))
+	}
+	return symmetryNames[s]
+}
+
+// Apply maps a single cell through the symmetry about the origin.
+func (s Symmetry) Apply(cell [2]int) [2]int {
+	m := matrices[s]
+	return [2]int{
+		m[0][0]*cell[0] + m[0][1]*cell[1],
+		m[1][0]*cell[0] + m[1][1]*cell[1],
+	}
+}
+
+// Then returns the symmetry equivalent to applying s followed by t.
+func (s Symmetry) Then(t Symmetry) Symmetry {
+	a, b := matrices[t], matrices[s]
+	var product [2][2]int
+	for i := range 2 {
+		for j := range 2 {
+			product[i][j] = a[i][0]*b[0][j] + a[i][1]*b[1][j]
+		}
+	}
+
+	// D4 is closed under composition, so the product is always one of its elements
+	return Symmetry(slices.Index(matrices[:], product))
+}
+
+// Inverse returns the symmetry that undoes s.
+func (s Symmetry) Inverse() Symmetry {
+	for _, t := range D4 {
+		if s.Then(t) == Identity {
+			return t
+		}
+	}
+
+	panic("unreachable: every element of D4 has an inverse")
+}
+
+// Parse reads a polyomino from its ASCII drawing, where '#' marks a covered cell
+// and '.' marks an empty one.
+func Parse(lines []string) (Polyomino, error) {
+	var p Polyomino
+	for row, line := range lines {
+		for col, char := range line {
+			switch char {
+			case '#':
+				p = append(p, [2]int{row, col})
+			case '.':
+			default:
+				return nil, fmt.Errorf("invalid character %q at row %d, column %d", char, row, col)
+			}
+		}
+	}
+	if len(p) == 0 {
+		return nil, errors.New("polyomino has no cells")
+	}
+
+	return p.Normalize(), nil
+}
+
+// String draws the normalized polyomino as ASCII rows of '#' and '.' separated by newlines.
+func (p Polyomino) String() string {
+	if len(p) == 0 {
+		return ""
+	}
+
+	n := p.Normalize()
+	height, width := n.BoundingBox()
+	rows := make([][]byte, height)
+	for i := range rows {
+		rows[i] = []byte(strings.Repeat(".", width))
+	}
+	for _, cell := range n {
+		rows[cell[0]][cell[1]] = '#'
+	}
+
+	var sb strings.Builder
+	for i, row := range rows {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		sb.Write(row)
+	}
+
+	return sb.String()
+}
+
+// Area returns the number of cells in the polyomino.
+func (p Polyomino) Area() int {
+	return len(p)
+}
+
+// BoundingBox returns the height and width of the smallest rectangle containing the polyomino.
+func (p Polyomino) BoundingBox() (int, int) {
+	if len(p) == 0 {
+		return 0, 0
+	}
+
+	minRow, minCol := p[0][0], p[0][1]
+	maxRow, maxCol := p[0][0], p[0][1]
+	for _, cell := range p[1:] {
+		minRow, maxRow = min(minRow, cell[0]), max(maxRow, cell[0])
+		minCol, maxCol = min(minCol, cell[1]), max(maxCol, cell[1])
+	}
+
+	return maxRow - minRow + 1, maxCol - minCol + 1
+}
+
+// Normalize shifts the polyomino so its minimum row and column are both 0, and sorts
+// its cells in row-major order. The first cell is therefore the top-most, left-most one.
+func (p Polyomino) Normalize() Polyomino {
+	if len(p) == 0 {
+		return nil
+	}
+
+	minRow, minCol := p[0][0], p[0][1]
+	for _, cell := range p {
+		minRow = min(minRow, cell[0])
+		minCol = min(minCol, cell[1])
+	}
+
+	normalized := make(Polyomino, len(p))
+	for i, cell := range p {
+		normalized[i] = [2]int{cell[0] - minRow, cell[1] - minCol}
+	}
+	slices.SortFunc(normalized, func(a, b [2]int) int {
+		if a[0] != b[0] {
+			return a[0] - b[0]
+		}
+		return a[1] - b[1]
+	})
+
+	return normalized
+}
+
+// Key returns the hashable key of the polyomino.
+func (p Polyomino) Key() Key {
+	return Key(p.String())
+}
+
+// Transform applies the symmetry to every cell and normalizes the result.
+func (p Polyomino) Transform(s Symmetry) Polyomino {
+	transformed := make(Polyomino, len(p))
+	for i, cell := range p {
+		transformed[i] = s.Apply(cell)
+	}
+
+	return transformed.Normalize()
+}
+
+// Orientations returns every distinct orientation of the polyomino under D4, each normalized,
+// in the order their symmetries appear in D4. The first orientation is the polyomino itself.
+func (p Polyomino) Orientations() []Polyomino {
+	var orientations []Polyomino
+	seen := make(map[Key]bool)
+	for _, s := range D4 {
+		orientation := p.Transform(s)
+		if key := orientation.Key(); !seen[key] {
+			seen[key] = true
+			orientations = append(orientations, orientation)
+		}
+	}
+
+	return orientations
+}
+
+// Canonical returns the representative of the polyomino's D4 orbit, chosen as the
+// orientation with the smallest key. Congruent polyominoes share a canonical form.
+func (p Polyomino) Canonical() Polyomino {
+	var best Polyomino
+	var bestKey Key
+	for _, s := range D4 {
+		orientation := p.Transform(s)
+		if key := orientation.Key(); best == nil || key < bestKey {
+			best, bestKey = orientation, key
+		}
+	}
+
+	return best
+}
+
+// Stabilizer returns the subgroup of D4 that maps the polyomino onto itself.
+// The number of distinct orientations is 8 divided by the size of this subgroup.
+func (p Polyomino) Stabilizer() []Symmetry {
+	key := p.Normalize().Key()
+
+	var symmetries []Symmetry
+	for _, s := range D4 {
+		if p.Transform(s).Key() == key {
+			symmetries = append(symmetries, s)
+		}
+	}
+
+	return symmetries
+}
@@ -0,0 +1,97 @@
+package registry
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// param is a named solver parameter holding its current value as a string.
+type param struct {
+	value        string
+	defaultValue string
+	usage        string
+	isInt        bool
+}
+
+// paramTable holds the parameters declared by each solver, keyed by [day, part].
+var paramTable = map[[2]int]map[string]*param{}
+
+func registerParam(day, part int, name string, p *param) {
+	key := [2]int{day, part}
+	if paramTable[key] == nil {
+		paramTable[key] = map[string]*param{}
+	}
+	paramTable[key][name] = p
+}
+
+// RegisterIntParam declares an integer parameter accepted by the solver for the given day and part.
+func RegisterIntParam(day, part int, name string, defaultValue int, usage string) {
+	value := strconv.Itoa(defaultValue)
+	registerParam(day, part, name, &param{value: value, defaultValue: value, usage: usage, isInt: true})
+}
+
+// RegisterStringParam declares a string parameter accepted by the solver for the given day and part.
+func RegisterStringParam(day, part int, name string, defaultValue string, usage string) {
+	registerParam(day, part, name, &param{value: defaultValue, defaultValue: defaultValue, usage: usage})
+}
+
+// SetParam overrides the value of a parameter declared by the solver for the given day and part.
+// It fails if the solver does not declare the parameter, or if an integer parameter is given a
+// value that is not an integer.
+func SetParam(day, part int, name, value string) error {
+	p, exists := paramTable[[2]int{day, part}][name]
+	if !exists {
+		available := ParamUsage(day, part)
+		if len(available) == 0 {
+			return fmt.Errorf("day %d part %d does not accept any parameters", day, part)
+		}
+		return fmt.Errorf("unknown parameter %q for day %d part %d, available parameters:\n  %s",
+			name, day, part, strings.Join(available, "\n  "))
+	}
+	if p.isInt {
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("parameter %q must be an integer, got %q", name, value)
+		}
+	}
+
+	p.value = value
+	return nil
+}
+
+// IntParam returns the current value of an integer parameter declared by the solver for
+// the given day and part. It panics if the solver does not declare the parameter.
+func IntParam(day, part int, name string) int {
+	value, err := strconv.Atoi(lookupParam(day, part, name).value)
+	if err != nil {
+		panic(fmt.Sprintf("parameter %q of day %d part %d is not an integer", name, day, part))
+	}
+	return value
+}
+
+// StringParam returns the current value of a string parameter declared by the solver for
+// the given day and part. It panics if the solver does not declare the parameter.
+func StringParam(day, part int, name string) string {
+	return lookupParam(day, part, name).value
+}
+
+func lookupParam(day, part int, name string) *param {
+	p, exists := paramTable[[2]int{day, part}][name]
+	if !exists {
+		panic(fmt.Sprintf("day %d part %d does not declare parameter %q", day, part, name))
+	}
+	return p
+}
+
+// ParamUsage describes every parameter declared by the solver for the given day and part,
+// sorted by name, with one line per parameter.
+func ParamUsage(day, part int) []string {
+	var usage []string
+	for name, p := range paramTable[[2]int{day, part}] {
+		usage = append(usage, fmt.Sprintf("%s (default %q): %s", name, p.defaultValue, p.usage))
+	}
+	slices.Sort(usage)
+
+	return usage
+}
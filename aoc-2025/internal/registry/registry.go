@@ -1,9 +1,28 @@
 package registry
 
+import "io"
+
 type Solver func([]string) string
 
+// StreamSolver is a solver that reads its input incrementally, for inputs too large to load into memory.
+type StreamSolver func(io.Reader) string
+
 var table = map[int]map[int]Solver{}
 
+var streamTable = map[int]map[int]StreamSolver{}
+
+// verbose controls whether solvers print diagnostic output alongside their answers.
+var verbose bool
+
+// exportPath is the file that solvers supporting export write their intermediate results to.
+var exportPath string
+
+// renderPath is the file that solvers supporting rendering draw their solution to.
+var renderPath string
+
+// variant selects an alternative implementation for solvers that provide more than one.
+var variant string
+
 func Register(day, part int, fn Solver) {
 	if table[day] == nil {
 		table[day] = map[int]Solver{}
@@ -17,3 +36,61 @@ func Lookup(day, part int) Solver {
 	}
 	return table[day][part]
 }
+
+// RegisterStream registers a solver that can read its input as a stream. Days registering one should
+// still register a regular solver for the same part.
+func RegisterStream(day, part int, fn StreamSolver) {
+	if streamTable[day] == nil {
+		streamTable[day] = map[int]StreamSolver{}
+	}
+	streamTable[day][part] = fn
+}
+
+// LookupStream returns the streaming solver for the given day and part, or nil if there is none.
+func LookupStream(day, part int) StreamSolver {
+	if streamTable[day] == nil {
+		return nil
+	}
+	return streamTable[day][part]
+}
+
+// SetVerbose enables or disables diagnostic output for all solvers.
+func SetVerbose(v bool) {
+	verbose = v
+}
+
+// Verbose reports whether solvers should print diagnostic output.
+func Verbose() bool {
+	return verbose
+}
+
+// SetVariant selects the named implementation for solvers that provide alternatives.
+// Solvers fall back to their default implementation for names they do not recognize.
+func SetVariant(name string) {
+	variant = name
+}
+
+// Variant returns the name of the selected solver implementation, or "" for the default.
+func Variant() string {
+	return variant
+}
+
+// SetExportPath sets the file that solvers supporting export write their results to.
+func SetExportPath(path string) {
+	exportPath = path
+}
+
+// ExportPath returns the file that solvers should export their results to, or "" for none.
+func ExportPath() string {
+	return exportPath
+}
+
+// SetRenderPath sets the file that solvers supporting rendering draw their solution to.
+func SetRenderPath(path string) {
+	renderPath = path
+}
+
+// RenderPath returns the file that solvers should render their solution to, or "" for none.
+func RenderPath() string {
+	return renderPath
+}
@@ -7,64 +7,122 @@ import (
 	"strconv"
 )
 
-// Dial constants
+// Default dial configuration
 const dialSize = 100
 const startPos = 50
 
 func init() {
 	registry.Register(1, 1, SolveDay1Part1)
 	registry.Register(1, 2, SolveDay1Part2)
+
+	for part := 1; part <= 2; part++ {
+		registry.RegisterIntParam(1, part, "size", dialSize, "number of positions on the dial")
+		registry.RegisterIntParam(1, part, "start", startPos, "position the dial starts at")
+	}
 }
 
 func SolveDay1Part1(input []string) string {
+	dial, err := NewDial(registry.IntParam(1, 1, "size"), registry.IntParam(1, 1, "start"))
+	if err != nil {
+		return "invalid dial: " + err.Error()
+	}
 	onlyCountDirect := true
-	zeroCount := zeroCount(input, onlyCountDirect)
+	zeroCount, err := zeroCount(input, dial, onlyCountDirect)
+	if err != nil {
+		return "invalid rotation: " + err.Error()
+	}
 	return fmt.Sprintf("Dial landed directly on position 0 a total of %d times", zeroCount)
 }
 
 func SolveDay1Part2(input []string) string {
+	dial, err := NewDial(registry.IntParam(1, 2, "size"), registry.IntParam(1, 2, "start"))
+	if err != nil {
+		return "invalid dial: " + err.Error()
+	}
 	onlyCountDirect := false
-	zeroCount := zeroCount(input, onlyCountDirect)
+	zeroCount, err := zeroCount(input, dial, onlyCountDirect)
+	if err != nil {
+		return "invalid rotation: " + err.Error()
+	}
 	return fmt.Sprintf("Dial encountered position 0 a total of %d times", zeroCount)
 }
 
-// zeroCount takes a list of rotation instructions and returns the number of times
-// the dial encounters position 0. If onlyCountDirect is true, it counts only direct landings on 0.
-func zeroCount(rotations []string, onlyCountDirect bool) int {
+// Dial is a circular dial with positions numbered from 0 to one less than its size.
+type Dial struct {
+	size     int
+	position int
+}
+
+// NewDial returns a dial with the given number of positions, pointing at the start position.
+func NewDial(size, start int) (*Dial, error) {
+	if size < 1 {
+		return nil, fmt.Errorf("size must be positive, got %d", size)
+	}
+	if start < 0 || start >= size {
+		return nil, fmt.Errorf("start position %d is not between 0 and %d", start, size-1)
+	}
+	return &Dial{size: size, position: start}, nil
+}
+
+// Position returns the position the dial is currently pointing at.
+func (d *Dial) Position() int {
+	return d.position
+}
+
+// Step rotates the dial by a number of clicks in the given direction (-1 for left, 1 for right),
+// returning its new position and the number of times it pointed at position 0 along the way,
+// including if it finishes there.
+func (d *Dial) Step(dir, clicks int) (int, int) {
+	// Calculate the minimum number of clicks it would take to reach position 0 going in the given direction
+	// This is equivalent to the smallest k ≥ 1 such that position + dir*k ≡ 0 (mod size)
+	minClicks := ((-dir*d.position)%d.size + d.size) % d.size
+	if minClicks == 0 {
+		// If already at position 0, the next encounter would be after a full rotation
+		minClicks = d.size
+	}
+
+	zeroCrossings := 0
+	if clicks >= minClicks {
+		// Add 1 for the first encounter, then count additional full rotations
+		zeroCrossings = 1 + (clicks-minClicks)/d.size
+	}
+
+	d.position = ((d.position+dir*clicks)%d.size + d.size) % d.size
+	return d.position, zeroCrossings
+}
+
+// zeroCount takes a list of rotation instructions, applies them to the dial and returns the number
+// of times the dial encounters position 0. If onlyCountDirect is true, it counts only direct landings
+// on 0. Blank lines are skipped, and any other invalid rotation is returned as an error. In verbose
+// mode, each rotation is traced with the dial's position before and after it.
+func zeroCount(rotations []string, dial *Dial, onlyCountDirect bool) (int, error) {
 	count := 0
-	currPos := startPos
 
-	for _, move := range rotations {
+	for i, move := range rotations {
+		if move == "" {
+			continue
+		}
 		dir, clicks, err := parseMove(move)
 		if err != nil {
-			continue // Just ignore inavalid rotations
+			return 0, fmt.Errorf("line %d (%q): %w", i+1, move, err)
 		}
 
-		newPos := rotateDial(currPos, dir, clicks)
+		prevPos := dial.Position()
+		newPos, zeroCrossings := dial.Step(dir, clicks)
+		if registry.Verbose() {
+			fmt.Printf("%s: %d -> %d (zero crossings: %d)\n", move, prevPos, newPos, zeroCrossings)
+		}
 
 		if onlyCountDirect {
 			if newPos == 0 {
 				count++
 			}
 		} else {
-			// Calculate the minimum number of clicks it would take to reach position 0 going in the given direction
-			// This is equivalent to the smallest k ≥ 1 such that currPos + dir*k ≡ 0 (mod dialSize)
-			minClicks := ((-dir*currPos)%dialSize + dialSize) % dialSize
-			if minClicks == 0 {
-				// If already at position 0, the next encounter would be after a full rotation
-				minClicks = dialSize
-			}
-
-			if clicks >= minClicks {
-				// Add 1 for the first encounter, then count additional full rotations
-				count += 1 + (clicks-minClicks)/dialSize
-			}
+			count += zeroCrossings
 		}
-
-		currPos = newPos
 	}
 
-	return count
+	return count, nil
 }
 
 // parseMove takes a move instruction string (e.g., "L10" or "R5") and returns the direction
@@ -91,9 +149,3 @@ func parseMove(move string) (int, int, error) {
 
 	return dir, clicks, nil
 }
-
-// rotateDial calculates the new position of the dial after rotation by a given number of clicks
-// in a specified direction, starting from the current position.
-func rotateDial(currPos, dir, clicks int) int {
-	return (currPos + dir*clicks + dialSize) % dialSize
-}
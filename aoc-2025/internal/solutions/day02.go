@@ -10,30 +10,362 @@ import (
 func init() {
 	registry.Register(2, 1, SolveDay2Part1)
 	registry.Register(2, 2, SolveDay2Part2)
+	registry.RegisterStringParam(2, 1, "rule", "repeats(2)", "rule expression that invalid IDs match")
+	registry.RegisterStringParam(2, 2, "rule", "repeats(2+)", "rule expression that invalid IDs match")
 }
 
 func SolveDay2Part1(input []string) string {
-	invalidIDSum := calculateInvalidIDSum(input[0], isInvalidIDPart1)
-	return fmt.Sprintf("The sum of all the invalid IDs is %d", invalidIDSum)
+	return solveDay2(input[0], registry.StringParam(2, 1, "rule"))
 }
 
 func SolveDay2Part2(input []string) string {
-	invalidIDSum := calculateInvalidIDSum(input[0], isInvalidIDPart2)
-	return fmt.Sprintf("The sum of all the invalid IDs is %d", invalidIDSum)
+	return solveDay2(input[0], registry.StringParam(2, 2, "rule"))
 }
 
-// calculateInvalidIDSum takes a string representing ID ranges and
-// returns the sum of all invalid IDs within those ranges according to the
-// specified invalid ID function.
-func calculateInvalidIDSum(ranges string, invalidIDFunc func(int) bool) int {
+// solveDay2 sums the IDs within the ranges that match the rule expression.
+func solveDay2(ranges string, ruleExpr string) string {
+	rule, err := parseIDRule(ruleExpr)
+	if err != nil {
+		return "invalid ID rule: " + err.Error()
+	}
 	idRanges, err := parseIDRanges(ranges)
 	if err != nil {
-		fmt.Printf("Error parsing ID ranges: %v\n", err)
+		return "invalid ID ranges: " + err.Error()
+	}
+
+	invalidIDSum := calculateInvalidIDSum(idRanges, rule)
+	return fmt.Sprintf("The sum of all the invalid IDs is %d", invalidIDSum)
+}
+
+// idRule decides whether an ID is invalid from its digits in the given base.
+type idRule interface {
+	matches(digits string, base int) bool
+}
+
+// repeatRule matches IDs made of some block of digits repeated between minRepeats and
+// maxRepeats times, where a maxRepeats of 0 means there is no upper limit.
+type repeatRule struct {
+	minRepeats int
+	maxRepeats int
+}
+
+func (r repeatRule) acceptsRepeats(repeats int) bool {
+	return repeats >= r.minRepeats && (r.maxRepeats == 0 || repeats <= r.maxRepeats)
+}
+
+func (r repeatRule) matches(digits string, _ int) bool {
+	numDigits := len(digits)
+	for repeats := 1; repeats <= numDigits; repeats++ {
+		if numDigits%repeats == 0 && r.acceptsRepeats(repeats) &&
+			strings.Repeat(digits[:numDigits/repeats], repeats) == digits {
+			return true
+		}
+	}
+	return false
+}
+
+// palindromeRule matches IDs whose digits read the same forwards and backwards.
+type palindromeRule struct{}
+
+func (palindromeRule) matches(digits string, _ int) bool {
+	for i, j := 0, len(digits)-1; i < j; i, j = i+1, j-1 {
+		if digits[i] != digits[j] {
+			return false
+		}
+	}
+	return true
+}
+
+// baseRule matches IDs whose digits in a different base match the inner rule.
+type baseRule struct {
+	base int
+	rule idRule
+}
+
+func (r baseRule) matches(digits string, base int) bool {
+	id, err := strconv.ParseInt(digits, base, 64)
+	if err != nil {
+		return false
+	}
+	return r.rule.matches(strconv.FormatInt(id, r.base), r.base)
+}
+
+// andRule matches IDs that match all of its rules.
+type andRule []idRule
+
+func (rules andRule) matches(digits string, base int) bool {
+	for _, rule := range rules {
+		if !rule.matches(digits, base) {
+			return false
+		}
+	}
+	return true
+}
+
+// orRule matches IDs that match any of its rules.
+type orRule []idRule
+
+func (rules orRule) matches(digits string, base int) bool {
+	for _, rule := range rules {
+		if rule.matches(digits, base) {
+			return true
+		}
+	}
+	return false
+}
+
+// notRule matches IDs that do not match its rule.
+type notRule struct {
+	rule idRule
+}
+
+func (r notRule) matches(digits string, base int) bool {
+	return !r.rule.matches(digits, base)
+}
+
+// parseIDRule parses a rule expression, which is one of:
+//
+//	repeats(k)       a block of digits repeated exactly k times
+//	repeats(k+)      a block of digits repeated at least k times
+//	palindrome       digits that read the same in both directions
+//	base(b, rule)    the rule applied to the digits in base b (2-36)
+//	and(rule, ...)   all of the rules
+//	or(rule, ...)    any of the rules
+//	not(rule)        anything but the rule
+func parseIDRule(expr string) (idRule, error) {
+	p := &ruleParser{expr: expr}
+	rule, err := p.parseRule()
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpaces(); p.pos < len(p.expr) {
+		return nil, fmt.Errorf("unexpected %q at position %d", p.expr[p.pos:], p.pos+1)
+	}
+	return rule, nil
+}
+
+// ruleParser is a recursive descent parser over a rule expression.
+type ruleParser struct {
+	expr string
+	pos  int
+}
+
+func (p *ruleParser) parseRule() (idRule, error) {
+	p.skipSpaces()
+	start := p.pos
+	for p.pos < len(p.expr) && p.expr[p.pos] >= 'a' && p.expr[p.pos] <= 'z' {
+		p.pos++
+	}
+	name := p.expr[start:p.pos]
+
+	switch name {
+	case "palindrome":
+		return palindromeRule{}, nil
+	case "repeats":
+		if err := p.expect('('); err != nil {
+			return nil, err
+		}
+		repeats, err := p.parseInt()
+		if err != nil {
+			return nil, err
+		}
+		if repeats < 1 {
+			return nil, fmt.Errorf("repetition count must be positive, got %d", repeats)
+		}
+		rule := repeatRule{minRepeats: repeats, maxRepeats: repeats}
+		if p.skipSpaces(); p.pos < len(p.expr) && p.expr[p.pos] == '+' {
+			rule.maxRepeats = 0
+			p.pos++
+		}
+		return rule, p.expect(')')
+	case "base":
+		if err := p.expect('('); err != nil {
+			return nil, err
+		}
+		base, err := p.parseInt()
+		if err != nil {
+			return nil, err
+		}
+		if base < 2 || base > 36 {
+			return nil, fmt.Errorf("base must be between 2 and 36, got %d", base)
+		}
+		if err := p.expect(','); err != nil {
+			return nil, err
+		}
+		rule, err := p.parseRule()
+		if err != nil {
+			return nil, err
+		}
+		return baseRule{base: base, rule: rule}, p.expect(')')
+	case "not":
+		if err := p.expect('('); err != nil {
+			return nil, err
+		}
+		rule, err := p.parseRule()
+		if err != nil {
+			return nil, err
+		}
+		return notRule{rule: rule}, p.expect(')')
+	case "and", "or":
+		rules, err := p.parseRuleList()
+		if err != nil {
+			return nil, err
+		}
+		if name == "and" {
+			return andRule(rules), nil
+		}
+		return orRule(rules), nil
+	case "":
+		return nil, fmt.Errorf("expected rule at position %d", p.pos+1)
+	}
+
+	return nil, fmt.Errorf("unknown rule %q at position %d", name, start+1)
+}
+
+// parseRuleList parses a parenthesised, comma-separated list of one or more rules.
+func (p *ruleParser) parseRuleList() ([]idRule, error) {
+	if err := p.expect('('); err != nil {
+		return nil, err
+	}
+
+	var rules []idRule
+	for {
+		rule, err := p.parseRule()
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+
+		if p.skipSpaces(); p.pos < len(p.expr) && p.expr[p.pos] == ',' {
+			p.pos++
+			continue
+		}
+		return rules, p.expect(')')
+	}
+}
+
+func (p *ruleParser) parseInt() (int, error) {
+	p.skipSpaces()
+	start := p.pos
+	for p.pos < len(p.expr) && p.expr[p.pos] >= '0' && p.expr[p.pos] <= '9' {
+		p.pos++
+	}
+	if start == p.pos {
+		return 0, fmt.Errorf("expected number at position %d", p.pos+1)
+	}
+	return strconv.Atoi(p.expr[start:p.pos])
+}
+
+func (p *ruleParser) expect(char byte) error {
+	if p.skipSpaces(); p.pos >= len(p.expr) || p.expr[p.pos] != char {
+		return fmt.Errorf("expected %q at position %d", char, p.pos+1)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *ruleParser) skipSpaces() {
+	for p.pos < len(p.expr) && p.expr[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+// repeatedIDSum returns the sum of all IDs within the ranges that are made of some block of digits
+// repeated a number of times accepted by isRepeatCount. Rather than scanning each ID, the repeated
+// IDs are enumerated arithmetically for every ID length, so the cost does not depend on range size.
+func repeatedIDSum(ranges [][2]int, isRepeatCount func(int) bool) int {
+	sum := 0
+	for _, r := range ranges {
+		// Handle each ID length in the range separately
+		for numDigits := digitCount(r[0]); numDigits <= digitCount(r[1]); numDigits++ {
+			lower := max(r[0], pow10(numDigits-1))
+			upper := min(r[1], pow10(numDigits)-1)
+			sum += repeatedIDSumOfLength(lower, upper, numDigits, isRepeatCount)
+		}
+	}
+
+	return sum
+}
+
+// repeatedIDSumOfLength returns the sum of the repeated IDs between lower and upper, which all have
+// the given number of digits. An ID whose smallest repeating block has length b can be read as a block
+// repeated k times for every k dividing numDigits/b, so IDs are grouped by their smallest block length.
+// The sum for each group is found by inclusion–exclusion: the IDs with a block of length b, less those
+// whose smallest block is a proper divisor of b.
+func repeatedIDSumOfLength(lower, upper, numDigits int, isRepeatCount func(int) bool) int {
+	sum := 0
+	smallestBlockSums := map[int]int{} // Sum of IDs by the length of their smallest repeating block
+	for blockLen := 1; blockLen <= numDigits; blockLen++ {
+		if numDigits%blockLen != 0 {
+			continue
+		}
+
+		blockSum := periodicIDSum(lower, upper, blockLen, numDigits/blockLen)
+		for shorterLen, shorterSum := range smallestBlockSums {
+			if blockLen%shorterLen == 0 {
+				blockSum -= shorterSum // Already counted with a shorter block
+			}
+		}
+		smallestBlockSums[blockLen] = blockSum
+
+		for repeats := 1; repeats <= numDigits/blockLen; repeats++ {
+			if (numDigits/blockLen)%repeats == 0 && isRepeatCount(repeats) {
+				sum += blockSum
+				break
+			}
+		}
+	}
+
+	return sum
+}
+
+// periodicIDSum returns the sum of the IDs between lower and upper that consist of a block of
+// blockLen digits (without leading zeros) repeated the given number of times. Each such ID is
+// the block multiplied by 1 + 10^blockLen + 10^(2*blockLen) + ..., so the matching blocks form
+// a contiguous range whose sum is an arithmetic series.
+func periodicIDSum(lower, upper, blockLen, repeats int) int {
+	multiplier := 0
+	for i := 0; i < repeats; i++ {
+		multiplier = multiplier*pow10(blockLen) + 1
+	}
+
+	minBlock := max(pow10(blockLen-1), (lower+multiplier-1)/multiplier)
+	maxBlock := min(pow10(blockLen)-1, upper/multiplier)
+	if minBlock > maxBlock {
 		return 0
 	}
+	return multiplier * (minBlock + maxBlock) * (maxBlock - minBlock + 1) / 2
+}
+
+// digitCount returns the number of decimal digits in a non-negative integer.
+func digitCount(n int) int {
+	count := 1
+	for n >= 10 {
+		n /= 10
+		count++
+	}
+	return count
+}
+
+// pow10 returns 10 raised to a non-negative integer power.
+func pow10(exp int) int {
+	result := 1
+	for i := 0; i < exp; i++ {
+		result *= 10
+	}
+	return result
+}
+
+// calculateInvalidIDSum returns the sum of all IDs within the ranges that the rule matches in base 10.
+// Rules that only count repetitions are summed arithmetically, unless the reference is requested,
+// and any other rule is checked against every ID in the ranges.
+func calculateInvalidIDSum(ranges [][2]int, rule idRule) int {
+	if repeats, ok := rule.(repeatRule); ok && registry.Variant() != "reference" {
+		return repeatedIDSum(ranges, repeats.acceptsRepeats)
+	}
 
 	sum := 0
-	for _, id := range filterInvalidIDs(idRanges, invalidIDFunc) {
+	for _, id := range filterInvalidIDs(ranges, rule) {
 		sum += id
 	}
 
@@ -41,14 +373,13 @@ func calculateInvalidIDSum(ranges string, invalidIDFunc func(int) bool) int {
 }
 
 // filterInvalidIDs takes a slice of [2]int representing ID ranges and
-// returns a slice of all invalid IDs within those ranges according to the
-// specified invalid ID function.
-func filterInvalidIDs(ranges [][2]int, invalidIDFunc func(int) bool) []int {
+// returns a slice of all IDs within those ranges that the rule matches.
+func filterInvalidIDs(ranges [][2]int, rule idRule) []int {
 	var invalidIDs []int
 	for _, r := range ranges {
 		lower, upper := r[0], r[1]
 		for id := lower; id <= upper; id++ {
-			if invalidIDFunc(id) {
+			if rule.matches(strconv.Itoa(id), 10) {
 				invalidIDs = append(invalidIDs, id)
 			}
 		}
@@ -57,27 +388,6 @@ func filterInvalidIDs(ranges [][2]int, invalidIDFunc func(int) bool) []int {
 	return invalidIDs
 }
 
-// isInvalidIDPart1 checks if a given ID is invalid based on the criteria
-// of being made only of some sequence of digits repeated twice.
-func isInvalidIDPart1(id int) bool {
-	idString := strconv.Itoa(id)
-	numDigits := len(idString)
-	return numDigits%2 == 0 && idString[:numDigits/2] == idString[numDigits/2:]
-}
-
-// isInvalidIDPart2 checks if a given ID is invalid based on the criteria
-// of being made only of some sequence of digits repeated at least twice.
-func isInvalidIDPart2(id int) bool {
-	idString := strconv.Itoa(id)
-	idLength := len(idString)
-
-	// If the original ID string appears in the repeated string,
-	// excluding the first and last characters, then it is made
-	// of some sequence repeated at least twice.
-	repeatedID := idString + idString
-	return strings.Contains(repeatedID[1:2*idLength-1], idString)
-}
-
 // parseIDRanges takes a line containing ID ranges in the format "1-3,5-7,10-15"
 // and returns a slice of [2]int representing the lower and upper bounds of each range.
 func parseIDRanges(line string) ([][2]int, error) {
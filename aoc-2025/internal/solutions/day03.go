@@ -1,72 +1,211 @@
 package solutions
 
 import (
+	"aoc-2025/internal/checked"
 	"aoc-2025/internal/registry"
 	"fmt"
-	"math"
+	"math/big"
+	"strings"
 )
 
 func init() {
 	registry.Register(3, 1, SolveDay3Part1)
 	registry.Register(3, 2, SolveDay3Part2)
+
+	registry.RegisterIntParam(3, 1, "batteries", 2, "number of batteries to select from each bank")
+	registry.RegisterIntParam(3, 2, "batteries", 12, "number of batteries to select from each bank")
 }
 
 func SolveDay3Part1(input []string) string {
-	numBatteries := 2
-	batteryBanks := parseBatteryBanks(input)
-	outputJoltage := totalJoltage(batteryBanks, numBatteries)
-	return fmt.Sprintf("The total maximum joltage using %d batteries per bank is %d", numBatteries, outputJoltage)
+	return solveDay3(input, registry.IntParam(3, 1, "batteries"))
 }
 
 func SolveDay3Part2(input []string) string {
-	numBatteries := 12
+	return solveDay3(input, registry.IntParam(3, 2, "batteries"))
+}
+
+// solveDay3 totals the maximum joltage of every bank when selecting the given number of batteries.
+func solveDay3(input []string, numBatteries int) string {
 	batteryBanks := parseBatteryBanks(input)
-	outputJoltage := totalJoltage(batteryBanks, numBatteries)
-	return fmt.Sprintf("The total maximum joltage using %d batteries per bank is %d", numBatteries, outputJoltage)
+	for i, bank := range batteryBanks {
+		if numBatteries < 0 || numBatteries > len(bank) {
+			return fmt.Sprintf("cannot select %d batteries from bank %d, which has %d", numBatteries, i+1, len(bank))
+		}
+	}
+
+	// Use the dynamic programming solution if the reference is requested
+	if registry.Variant() == "reference" {
+		outputJoltage, err := checked.WithFallback(
+			func(arith checked.Int64) (int64, error) { return totalJoltageDP(batteryBanks, numBatteries, arith) },
+			func(arith checked.Big) (*big.Int, error) { return totalJoltageDP(batteryBanks, numBatteries, arith) },
+		)
+		if err != nil {
+			return "failed to total joltage: " + err.Error()
+		}
+		return fmt.Sprintf("The total maximum joltage using %d batteries per bank is %s", numBatteries, outputJoltage)
+	}
+
+	// Select the batteries up front, so that falling back to arbitrary precision only redoes the sum
+	selections := make([][]int, len(batteryBanks))
+	for i, bank := range batteryBanks {
+		selections[i] = selectBatteries(bank, numBatteries)
+	}
+
+	// Joltages of more than 18 digits do not fit in an int64, so fall back to arbitrary precision
+	outputJoltage, err := checked.WithFallback(
+		func(arith checked.Int64) (int64, error) { return totalJoltage(selections, arith) },
+		func(arith checked.Big) (*big.Int, error) { return totalJoltage(selections, arith) },
+	)
+	if err != nil {
+		return "failed to total joltage: " + err.Error()
+	}
+
+	if registry.Verbose() {
+		for i, selected := range selections {
+			fmt.Printf("Bank %d: selected %v for %s jolts\n", i+1, selected, formatJoltage(selected))
+		}
+	}
+	return fmt.Sprintf("The total maximum joltage using %d batteries per bank is %s", numBatteries, outputJoltage)
+}
+
+// totalJoltage sums the joltages produced by the batteries selected from each bank, using the
+// given arithmetic.
+func totalJoltage[T any](selections [][]int, arith checked.Arithmetic[T]) (T, error) {
+	total := arith.Zero()
+	for _, selected := range selections {
+		joltage, err := joltageOf(selected, arith)
+		if err != nil {
+			return total, err
+		}
+		if total, err = arith.Add(total, joltage); err != nil {
+			return total, err
+		}
+	}
+
+	return total, nil
+}
+
+// selectBatteries selects the specified number of batteries from the bank that give the maximum
+// joltage, returning the selected battery joltages in order. The best selection is the
+// lexicographically largest subsequence of the given length, which is built in linear time by
+// keeping a stack of selected batteries that decreases from the bottom, popping any battery
+// smaller than the next one while enough batteries remain to fill the selection.
+func selectBatteries(batteries []int, numBatteries int) []int {
+	selected := make([]int, 0, numBatteries)
+	for i, battery := range batteries {
+		remaining := len(batteries) - i
+		for len(selected) > 0 && selected[len(selected)-1] < battery && len(selected)-1+remaining >= numBatteries {
+			selected = selected[:len(selected)-1]
+		}
+		if len(selected) < numBatteries {
+			selected = append(selected, battery)
+		}
+	}
+
+	return selected
+}
+
+// joltageOf computes the joltage that the selected batteries produce together, using the given
+// arithmetic. Each selected battery contributes the next digit of the joltage.
+func joltageOf[T any](selected []int, arith checked.Arithmetic[T]) (T, error) {
+	joltage := arith.Zero()
+	var err error
+	for _, battery := range selected {
+		if joltage, err = arith.Mul(joltage, arith.FromInt(10)); err != nil {
+			return joltage, err
+		}
+		if joltage, err = arith.Add(joltage, arith.FromInt(battery)); err != nil {
+			return joltage, err
+		}
+	}
+
+	return joltage, nil
+}
+
+// formatJoltage writes out the joltage that the selected batteries produce together in decimal,
+// which needs no arithmetic since each battery is one of its digits.
+func formatJoltage(selected []int) string {
+	var digits strings.Builder
+	for _, battery := range selected {
+		if digits.Len() > 0 || battery != 0 {
+			digits.WriteByte(byte('0' + battery))
+		}
+	}
+	if digits.Len() == 0 {
+		return "0"
+	}
+	return digits.String()
 }
 
-// totalJoltage calculates the total maximum joltage that can be achieved from
-// a collection of battery banks by selecting a specified number of batteries from each bank.
-func totalJoltage(banks [][]int, numBatteries int) int {
-	totalJoltage := 0
+// totalJoltageDP calculates the total maximum joltage that can be achieved from a collection of
+// battery banks by selecting a specified number of batteries from each bank, using the given arithmetic.
+func totalJoltageDP[T any](banks [][]int, numBatteries int, arith checked.Arithmetic[T]) (T, error) {
+	totalJoltage := arith.Zero()
 	for _, bank := range banks {
-		totalJoltage += maxJoltage(bank, numBatteries)
+		joltage, err := maxJoltageDP(bank, numBatteries, arith)
+		if err != nil {
+			return totalJoltage, err
+		}
+		if totalJoltage, err = arith.Add(totalJoltage, joltage); err != nil {
+			return totalJoltage, err
+		}
 	}
 
-	return totalJoltage
+	return totalJoltage, nil
 }
 
-// maxJoltage calculates the maximum joltage that can be achieved by selecting
-// a specified number of batteries from the given bank of battery joltages.
-func maxJoltage(batteries []int, numBatteriesLeft int) int {
+// maxJoltageDP is the reference implementation of selectBatteries, which calculates the maximum
+// joltage that can be achieved by selecting a specified number of batteries from the given bank of
+// battery joltages by dynamic programming, using the given arithmetic. The bank must hold at least
+// that many batteries.
+func maxJoltageDP[T any](batteries []int, numBatteriesLeft int, arith checked.Arithmetic[T]) (T, error) {
 	// Memoization map to cache results
-	memo := make(map[[2]int]int)
+	memo := make(map[[2]int]T)
 
-	var computeMaxJoltage func(int, int) int
-	computeMaxJoltage = func(batteryIdx int, numBatteriesLeft int) int {
+	var computeMaxJoltage func(int, int) (T, error)
+	computeMaxJoltage = func(batteryIdx int, numBatteriesLeft int) (T, error) {
 		if numBatteriesLeft == 0 {
 			// Base case: no more batteries to select
-			return 0
-		}
-		if batteryIdx == len(batteries) {
-			// Base case: still more batteries to select, but no more
-			// batteries available in the bank (invalid state)
-			return -math.MaxInt
+			return arith.Zero(), nil
 		}
 
 		// Memoization key: [current battery index, number of batteries left to select]
 		key := [2]int{batteryIdx, numBatteriesLeft}
 		if val, exists := memo[key]; exists {
-			return val
+			return val, nil
 		}
 
-		// Recursively compute the maximum joltage by either skipping or taking the current battery
-		skipCurrent := computeMaxJoltage(batteryIdx+1, numBatteriesLeft)
-		takeCurrent := batteries[batteryIdx]*int(math.Pow10(numBatteriesLeft-1)) +
-			computeMaxJoltage(batteryIdx+1, numBatteriesLeft-1)
+		// Taking the current battery makes it the leading digit of the joltage still to select
+		place, err := arith.Pow(arith.FromInt(10), arith.FromInt(numBatteriesLeft-1))
+		if err != nil {
+			return place, err
+		}
+		leading, err := arith.Mul(arith.FromInt(batteries[batteryIdx]), place)
+		if err != nil {
+			return leading, err
+		}
+		rest, err := computeMaxJoltage(batteryIdx+1, numBatteriesLeft-1)
+		if err != nil {
+			return rest, err
+		}
+		best, err := arith.Add(leading, rest)
+		if err != nil {
+			return best, err
+		}
+
+		// Skipping the current battery is only possible while enough batteries remain after it
+		if len(batteries)-batteryIdx > numBatteriesLeft {
+			skipCurrent, err := computeMaxJoltage(batteryIdx+1, numBatteriesLeft)
+			if err != nil {
+				return skipCurrent, err
+			}
+			if arith.Cmp(skipCurrent, best) > 0 {
+				best = skipCurrent
+			}
+		}
 
-		memo[key] = max(skipCurrent, takeCurrent)
-		return memo[key]
+		memo[key] = best
+		return best, nil
 	}
 
 	return computeMaxJoltage(0, numBatteriesLeft)
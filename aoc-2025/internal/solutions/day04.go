@@ -1,96 +1,214 @@
 package solutions
 
 import (
+	"aoc-2025/internal/automaton"
 	"aoc-2025/internal/registry"
+	"aoc-2025/internal/svg"
 	"fmt"
+	"os"
+	"strconv"
+	"strings"
 )
 
 // Symbols that appear in the grid
 const paperRoll = '@'
 const emptySpace = '.'
+const removedRoll = 'x' // Marks rolls removed in the latest wave of exported snapshots
 
-// Directions for 8 neighboring cells
-var directions = [8][2]int{
-	{-1, -1}, {-1, 0}, {-1, 1},
-	{0, -1}, {0, 1},
-	{1, -1}, {1, 0}, {1, 1},
-}
+// Seconds between waves in rendered animations
+const waveDuration = 0.5
+
+// Default forklift access rule: a roll is accessible if fewer than 4 of its 8 neighbours are rolls
+const defaultNeighbourhood = "moore"
+const defaultRadius = 1
+const defaultThreshold = 4
 
 func init() {
 	registry.Register(4, 1, SolveDay4Part1)
 	registry.Register(4, 2, SolveDay4Part2)
+
+	for part := 1; part <= 2; part++ {
+		registry.RegisterStringParam(4, part, "neighbourhood", defaultNeighbourhood,
+			"cells counted as adjacent to a roll: moore or vonneumann")
+		registry.RegisterIntParam(4, part, "radius", defaultRadius, "distance of the furthest adjacent cells")
+		registry.RegisterIntParam(4, part, "threshold", defaultThreshold,
+			"number of adjacent rolls that make a roll inaccessible")
+		registry.RegisterStringParam(4, part, "wrap", "false", "whether the grid wraps around at its edges")
+	}
 }
 
 func SolveDay4Part1(input []string) string {
 	grid := parseGrid(input)
-	numPaperRolls := len(paperRollsAccessibleByForklift(grid))
+	forklift, threshold, err := newForkliftAutomaton(grid, 1)
+	if err != nil {
+		return "invalid forklift automaton: " + err.Error()
+	}
+
+	// Accessible rolls are the ones that would be removed in the first generation
+	numPaperRolls := len(forklift.Step(forkliftRule(threshold)))
 	return fmt.Sprintf("The number of paper rolls accessible by forklift is %d", numPaperRolls)
 }
 
 func SolveDay4Part2(input []string) string {
 	grid := parseGrid(input)
-	numPaperRolls := numPaperRollsRemoved(grid)
+	forklift, threshold, err := newForkliftAutomaton(grid, 2)
+	if err != nil {
+		return "invalid forklift automaton: " + err.Error()
+	}
+
+	// Rescan the whole grid after every wave if the reference is requested
+	if registry.Variant() == "reference" {
+		numPaperRolls := numPaperRollsRemoved(forklift, threshold)
+		return fmt.Sprintf("The total number of paper rolls removed by the forklift is %d", numPaperRolls)
+	}
+
+	waves := forklift.Erode(func(adjacentRolls int) bool { return adjacentRolls >= threshold })
+	numPaperRolls := 0
+	for i, wave := range waves {
+		numPaperRolls += len(wave)
+		if registry.Verbose() {
+			fmt.Printf("Wave %d: removed %d paper rolls\n", i+1, len(wave))
+		}
+	}
+
+	if err := exportRemovalWaves(registry.ExportPath(), grid, waves); err != nil {
+		return "failed to export removal waves: " + err.Error()
+	}
+	if path := registry.RenderPath(); path != "" {
+		if err := renderRemovalWaves(grid, waves).WriteFile(path); err != nil {
+			return "failed to render removal waves: " + err.Error()
+		}
+	}
 	return fmt.Sprintf("The total number of paper rolls removed by the forklift is %d", numPaperRolls)
 }
 
-// numPaperRollsRemoved calculates the total number of paper rolls that can be removed
-// from the grid by repeatedly removing accessible paper rolls until none remain.
-func numPaperRollsRemoved(grid [][]rune) int {
+// newForkliftAutomaton creates a cellular automaton whose live cells are the paper rolls in the grid,
+// with the neighbourhood configured by the part's parameters, and returns it along with the number of
+// adjacent rolls that make a roll inaccessible. The grid must be rectangular.
+func newForkliftAutomaton(grid [][]rune, part int) (*automaton.Automaton, int, error) {
+	neighbourhood, err := automaton.ParseNeighbourhood(registry.StringParam(4, part, "neighbourhood"),
+		registry.IntParam(4, part, "radius"))
+	if err != nil {
+		return nil, 0, err
+	}
+	wrap, err := strconv.ParseBool(registry.StringParam(4, part, "wrap"))
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid wrap setting: %w", err)
+	}
+
+	paperRolls := make([][]bool, len(grid))
+	for x, row := range grid {
+		paperRolls[x] = make([]bool, len(row))
+		for y, cell := range row {
+			paperRolls[x][y] = cell == paperRoll
+		}
+	}
+
+	forklift, err := automaton.New(paperRolls, neighbourhood, wrap)
+	if err != nil {
+		return nil, 0, err
+	}
+	return forklift, registry.IntParam(4, part, "threshold"), nil
+}
+
+// forkliftRule keeps a paper roll only while it is inaccessible to a forklift, which is when
+// it has at least the threshold number of adjacent paper rolls.
+func forkliftRule(threshold int) automaton.Rule {
+	return func(alive bool, adjacentRolls int) bool {
+		return alive && adjacentRolls >= threshold
+	}
+}
+
+// numPaperRollsRemoved is the reference implementation of removing paper rolls in waves, which
+// calculates the total number of paper rolls that can be removed by repeatedly removing accessible
+// paper rolls until none remain, examining every cell of the grid in each wave.
+func numPaperRollsRemoved(forklift *automaton.Automaton, threshold int) int {
 	removedCount := 0
-	paperRolls := paperRollsAccessibleByForklift(grid)
-	for len(paperRolls) > 0 {
+	for paperRolls := forklift.Step(forkliftRule(threshold)); len(paperRolls) > 0; {
 		removedCount += len(paperRolls)
-		removePaperRolls(grid, paperRolls)
-		paperRolls = paperRollsAccessibleByForklift(grid)
+		paperRolls = forklift.Step(forkliftRule(threshold))
 	}
 
 	return removedCount
 }
 
-// removePaperRolls removes the paper rolls at the specified locations
-// from the grid by marking them as empty.
-func removePaperRolls(grid [][]rune, locations [][2]int) {
-	for _, loc := range locations {
-		x, y := loc[0], loc[1]
-		grid[x][y] = emptySpace
+// exportRemovalWaves writes a text snapshot of the grid before the first wave and after each
+// wave to the file at path, with the rolls removed by a wave marked with an 'x'. Nothing is
+// written if the path is empty.
+func exportRemovalWaves(path string, grid [][]rune, waves [][][2]int) error {
+	if path == "" {
+		return nil
+	}
+
+	snapshot := make([][]rune, len(grid))
+	numPaperRolls := 0
+	for x, row := range grid {
+		snapshot[x] = append([]rune(nil), row...)
+		numPaperRolls += strings.Count(string(row), string(paperRoll))
 	}
-}
 
-// paperRollsAccessibleByForklift computes the coordinates of the paper rolls
-// in the grid that can be accessed by a forklift.
-func paperRollsAccessibleByForklift(grid [][]rune) [][2]int {
-	locations := [][2]int{}
-	for x := range len(grid) {
-		for y := range len(grid[0]) {
-			if grid[x][y] == paperRoll && isAccessibleByForklift(grid, x, y) {
-				locations = append(locations, [2]int{x, y})
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Initial grid: %d paper rolls\n", numPaperRolls)
+	writeSnapshot(&sb, snapshot)
+	for i, wave := range waves {
+		// Clear the rolls marked by the previous wave before marking this one's
+		for x, row := range snapshot {
+			for y, cell := range row {
+				if cell == removedRoll {
+					snapshot[x][y] = emptySpace
+				}
 			}
 		}
+		for _, loc := range wave {
+			snapshot[loc[0]][loc[1]] = removedRoll
+		}
+
+		numPaperRolls -= len(wave)
+		fmt.Fprintf(&sb, "\nWave %d: removed %d paper rolls, leaving %d\n", i+1, len(wave), numPaperRolls)
+		writeSnapshot(&sb, snapshot)
 	}
 
-	return locations
+	return os.WriteFile(path, []byte(sb.String()), 0o644)
+}
+
+// writeSnapshot writes the grid to the builder, one row per line.
+func writeSnapshot(sb *strings.Builder, grid [][]rune) {
+	for _, row := range grid {
+		sb.WriteString(string(row))
+		sb.WriteByte('\n')
+	}
 }
 
-// isAccessibleByForklift checks if a paper roll at position (x, y) can be accessed by a forklift
-// based on the surrounding paper rolls. If there are 4 or more adjacent paper rolls, it is not accessible.
-func isAccessibleByForklift(grid [][]rune, x, y int) bool {
-	rows, cols := len(grid), len(grid[0])
-	if x < 0 || x >= rows || y < 0 || y >= cols {
-		return false
-	}
-
-	paperRollCount := 0
-	for _, dir := range directions {
-		nx, ny := x+dir[0], y+dir[1]
-		if nx >= 0 && nx < rows && ny >= 0 && ny < cols && grid[nx][ny] == paperRoll {
-			paperRollCount++
-			if paperRollCount >= 4 {
-				return false
+// renderRemovalWaves draws the grid as an animated image in which each paper roll disappears
+// when the wave that removes it is reached, coloured from red for the first wave to blue for the
+// last. Rolls that are never removed are drawn in black.
+func renderRemovalWaves(grid [][]rune, waves [][][2]int) *svg.Document {
+	doc := svg.New()
+	doc.Rect(0, 0, float64(len(grid[0])), float64(len(grid)), svg.Style{Fill: "#fafafa"})
+
+	removedIn := map[[2]int]int{}
+	for i, wave := range waves {
+		for _, loc := range wave {
+			removedIn[loc] = i + 1
+		}
+	}
+
+	for x, row := range grid {
+		for y, cell := range row {
+			if cell != paperRoll {
+				continue
+			}
+
+			style := svg.Style{Fill: "#212121"}
+			if wave, removed := removedIn[[2]int{x, y}]; removed {
+				hue := 240 * float64(wave-1) / float64(max(len(waves)-1, 1))
+				style = svg.Style{Fill: fmt.Sprintf("hsl(%.0f, 70%%, 50%%)", hue), HideAt: float64(wave) * waveDuration}
 			}
+			doc.Rect(float64(y)+0.1, float64(x)+0.1, 0.8, 0.8, style)
 		}
 	}
 
-	return true
+	return doc
 }
 
 // parseGrid converts the input strings into a 2D grid of runes.
@@ -2,7 +2,9 @@ package solutions
 
 import (
 	"aoc-2025/internal/registry"
+	"bufio"
 	"fmt"
+	"io"
 	"sort"
 	"strconv"
 	"strings"
@@ -11,49 +13,70 @@ import (
 func init() {
 	registry.Register(5, 1, SolveDay5Part1)
 	registry.Register(5, 2, SolveDay5Part2)
+	registry.RegisterStream(5, 1, SolveDay5Part1Stream)
+	registry.RegisterStream(5, 2, SolveDay5Part2Stream)
 }
 
 func SolveDay5Part1(input []string) string {
-	freshIngredientIDRanges, err := parseFreshIngredientIDRanges(input)
+	return SolveDay5Part1Stream(strings.NewReader(strings.Join(input, "\n")))
+}
+
+func SolveDay5Part2(input []string) string {
+	return SolveDay5Part2Stream(strings.NewReader(strings.Join(input, "\n")))
+}
+
+// SolveDay5Part1Stream reads the available ingredient IDs one line at a time, so that only the
+// merged fresh ID ranges are held in memory, however long the list of available IDs is.
+func SolveDay5Part1Stream(r io.Reader) string {
+	scanner := bufio.NewScanner(r)
+	mergedRanges, err := scanFreshIngredientIDRanges(scanner)
 	if err != nil {
 		return "invalid fresh ingredient ID range: " + err.Error()
 	}
-	availableIngredientIDs, err := parseAvailableIngredientIDs(input)
+	numFreshIngredients, err := numFreshIngredients(scanner, mergedRanges)
 	if err != nil {
 		return "invalid available ingredient ID: " + err.Error()
 	}
-	numFreshIngredients := numFreshIngredients(freshIngredientIDRanges, availableIngredientIDs)
 	return fmt.Sprintf("The number of fresh ingredients available is %d", numFreshIngredients)
 }
 
-func SolveDay5Part2(input []string) string {
-	freshIngredientIDRanges, err := parseFreshIngredientIDRanges(input)
+// SolveDay5Part2Stream reads only the fresh ID ranges, ignoring the available IDs that follow them.
+func SolveDay5Part2Stream(r io.Reader) string {
+	mergedRanges, err := scanFreshIngredientIDRanges(bufio.NewScanner(r))
 	if err != nil {
 		return "invalid fresh ingredient ID range: " + err.Error()
 	}
-	totalFresh := totalFreshIngredients(freshIngredientIDRanges)
+	totalFresh := totalFreshIngredients(mergedRanges)
 	return fmt.Sprintf("The total number of fresh ingredients across all ranges is %d", totalFresh)
 }
 
 // totalFreshIngredients computes the total number of fresh ingredient IDs
-// across all specified ranges of fresh ingredient IDs.
-func totalFreshIngredients(freshRanges [][2]int) int {
+// across all merged ranges of fresh ingredient IDs.
+func totalFreshIngredients(mergedRanges [][2]int) int {
 	total := 0
-	for _, r := range mergeIngredientIDRanges(freshRanges) {
+	for _, r := range mergedRanges {
 		total += r[1] - r[0] + 1
 	}
 	return total
 }
 
-// numFreshIngredients counts how many available ingredient IDs
-// fall within the specified ranges of fresh ingredient IDs.
-func numFreshIngredients(freshRanges [][2]int, availableIDs []int) int {
-	// Merge overlapping fresh ID ranges for efficient searching
-	mergedRanges := mergeIngredientIDRanges(freshRanges)
+// numFreshIngredients counts how many of the available ingredient IDs remaining in the
+// scanner, one per line, fall within the merged ranges of fresh ingredient IDs. Blank lines
+// are skipped.
+func numFreshIngredients(scanner *bufio.Scanner, mergedRanges [][2]int) (int, error) {
 	freshCount := 0
 
 	// Binary search each available ID in the merged fresh ID ranges
-	for _, id := range availableIDs {
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue // Tolerate blank lines, such as a trailing one at the end of the input
+		}
+		id, err := strconv.Atoi(line)
+		if err != nil {
+			return 0, err
+		}
+
 		// Find insertion point based on upper bound each range
 		i := sort.Search(len(mergedRanges), func(i int) bool {
 			return mergedRanges[i][1] >= id
@@ -64,11 +87,15 @@ func numFreshIngredients(freshRanges [][2]int, availableIDs []int) int {
 		}
 	}
 
-	return freshCount
+	return freshCount, scanner.Err()
 }
 
 // mergeIngredientIDRanges merges overlapping or contiguous ranges of ingredient IDs.
 func mergeIngredientIDRanges(ranges [][2]int) [][2]int {
+	if len(ranges) == 0 {
+		return nil
+	}
+
 	// Sort ranges by start ID
 	sort.Slice(ranges, func(i, j int) bool {
 		return ranges[i][0] < ranges[j][0]
@@ -91,50 +118,35 @@ func mergeIngredientIDRanges(ranges [][2]int) [][2]int {
 	return merged
 }
 
-// parseFreshIngredientIDRanges parses the ranges of fresh ingredient IDs
-// from the input, which appear before a blank line.
-func parseFreshIngredientIDRanges(input []string) ([][2]int, error) {
+// scanFreshIngredientIDRanges reads the ranges of fresh ingredient IDs from the scanner,
+// which appear before a blank line, and returns them merged. The scanner is left positioned
+// at the start of the available IDs section.
+func scanFreshIngredientIDRanges(scanner *bufio.Scanner) ([][2]int, error) {
 	var ranges [][2]int
-	for _, line := range input {
+	for scanner.Scan() {
+		line := scanner.Text()
 		if line == "" {
 			break // End of ID ranges section of input
 		}
 
-		idRange := strings.Split(line, "-")
-		start, err := strconv.Atoi(idRange[0])
+		startID, endID, found := strings.Cut(line, "-")
+		if !found {
+			return nil, fmt.Errorf("missing '-' in %q", line)
+		}
+		start, err := strconv.Atoi(startID)
 		if err != nil {
 			return nil, err
 		}
-		end, err := strconv.Atoi(idRange[1])
+		end, err := strconv.Atoi(endID)
 		if err != nil {
 			return nil, err
 		}
 
 		ranges = append(ranges, [2]int{start, end})
 	}
-
-	return ranges, nil
-}
-
-// parseAvailableIngredientIDs parses the list of available ingredient IDs
-// from the input, which appears after a blank line.
-func parseAvailableIngredientIDs(input []string) ([]int, error) {
-	var available []int
-	inAvailableSection := false
-	for _, line := range input {
-		if line == "" {
-			// Trigger the start of the available IDs section
-			inAvailableSection = true
-			continue
-		}
-		if inAvailableSection {
-			id, err := strconv.Atoi(line)
-			if err != nil {
-				return nil, err
-			}
-			available = append(available, id)
-		}
+	if err := scanner.Err(); err != nil {
+		return nil, err
 	}
 
-	return available, nil
+	return mergeIngredientIDRanges(ranges), nil
 }
@@ -1,9 +1,10 @@
 package solutions
 
 import (
+	"aoc-2025/internal/checked"
 	"aoc-2025/internal/registry"
 	"fmt"
-	"math"
+	"math/big"
 	"strconv"
 	"strings"
 )
@@ -14,73 +15,214 @@ func init() {
 }
 
 func SolveDay6Part1(input []string) string {
-	expressionSum := expressionSum(parseOperands(input), parseOperators(input))
-	return fmt.Sprintf("The total sum of all regular math answers is: %d", expressionSum)
+	expressions, err := parseWorksheet(input, readRowWise)
+	if err != nil {
+		return "failed to parse worksheet: " + err.Error()
+	}
+
+	// Products of many operands can overflow, so fall back to arbitrary precision if needed
+	expressionSum, err := checked.WithFallback(
+		func(arith checked.Int64) (int64, error) { return expressionSum(expressions, arith) },
+		func(arith checked.Big) (*big.Int, error) { return expressionSum(expressions, arith) },
+	)
+	if err != nil {
+		return "failed to evaluate expressions: " + err.Error()
+	}
+	return fmt.Sprintf("The total sum of all regular math answers is: %s", expressionSum)
 }
 
 func SolveDay6Part2(input []string) string {
-	expressionSum := cephalopodExpressionSum(input)
-	return fmt.Sprintf("The total sum of all cephalopod math answers is: %d", expressionSum)
+	// Cephalopod math is written right to left, with each operand's digits arranged vertically
+	expressions, err := parseWorksheet(input, readColumnWise)
+	if err != nil {
+		return "failed to parse worksheet: " + err.Error()
+	}
+
+	expressionSum, err := checked.WithFallback(
+		func(arith checked.Int64) (int64, error) { return expressionSum(expressions, arith) },
+		func(arith checked.Big) (*big.Int, error) { return expressionSum(expressions, arith) },
+	)
+	if err != nil {
+		return "failed to evaluate expressions: " + err.Error()
+	}
+	return fmt.Sprintf("The total sum of all cephalopod math answers is: %s", expressionSum)
 }
 
-// expressionSum computes the total sum of all evaluated expressions.
-func expressionSum(allOperands [][]int, operators []rune) int {
-	total := 0
-	var operands []int
+// operator is an operation that is applied across all operands of an expression.
+type operator int
+
+const (
+	add operator = iota
+	subtract
+	multiply
+	divide
+	maximum
+	minimum
+	power
+)
+
+// operatorTable maps the symbols that may appear in the operator row to their operators.
+var operatorTable = map[string]operator{
+	"+":   add,
+	"-":   subtract,
+	"*":   multiply,
+	"/":   divide,
+	"max": maximum,
+	"min": minimum,
+	"^":   power,
+}
+
+// expression is a single problem on the worksheet, along with the
+// (zero-based) column of the worksheet its block starts in.
+type expression struct {
+	operands []int
+	op       operator
+	column   int
+}
+
+// block is a problem's region of the worksheet: the text of each operand row between
+// its start and end columns, padded with spaces where a line is too short to reach them.
+type block struct {
+	rows  []string
+	start int
+	end   int // Exclusive
+}
 
-	for i, operator := range operators {
-		for _, row := range allOperands {
-			operands = append(operands, row[i])
+// expressionSum computes the total sum of all evaluated expressions using the given arithmetic.
+func expressionSum[T any](expressions []expression, arith checked.Arithmetic[T]) (T, error) {
+	total := arith.Zero()
+	for _, expr := range expressions {
+		result, err := evaluateExpression(expr.operands, expr.op, arith)
+		if err != nil {
+			return total, fmt.Errorf("column %d: %w", expr.column+1, err)
 		}
+		if total, err = arith.Add(total, result); err != nil {
+			return total, err
+		}
+	}
+
+	return total, nil
+}
 
-		total += evaluateExpression(operands, operator)
-		operands = operands[:0]
+// parseWorksheet splits the worksheet into problem blocks, parses each block's operator and
+// reads its operands with the given reading mode.
+func parseWorksheet(input []string, readOperands func(block) ([]int, error)) ([]expression, error) {
+	operatorRow := getOperatorRowIndex(input)
+	if operatorRow < 0 {
+		return nil, fmt.Errorf("no operator row found")
 	}
 
-	return total
+	var expressions []expression
+	for _, span := range segmentColumns(input[:operatorRow+1]) {
+		blk := block{start: span[0], end: span[1]}
+		for _, line := range input[:operatorRow] {
+			blk.rows = append(blk.rows, columnRange(line, span[0], span[1]))
+		}
+
+		op, err := parseOperator(columnRange(input[operatorRow], span[0], span[1]), span[0])
+		if err != nil {
+			return nil, err
+		}
+		operands, err := readOperands(blk)
+		if err != nil {
+			return nil, fmt.Errorf("column %d: %w", span[0]+1, err)
+		}
+		expressions = append(expressions, expression{operands: operands, op: op, column: span[0]})
+	}
+
+	return expressions, nil
 }
 
-// cephalopodExpressionSum computes the total sum of all evaluated expressions, but assumes
-// the input is formatted right to left with operand digits being arranged vertically.
-func cephalopodExpressionSum(input []string) int {
-	total := 0
-	var currOperands []int
+// segmentColumns splits the lines into blocks separated by columns that are blank in every line,
+// returning the start and (exclusive) end column of each block. Lines may have different lengths,
+// with missing characters treated as spaces, and blocks may be separated by any number of columns.
+func segmentColumns(lines []string) [][2]int {
+	width := 0
+	for _, line := range lines {
+		width = max(width, len(line))
+	}
 
-	// For cephalopod expressions, it will be easier to operate on the raw input grid instead
-	// of pre-fetching parsed integer operands due to the vertical alignment of the digits
-	operatorRow := getOperatorRowIndex(input)
+	var spans [][2]int
+	blockStart := -1
+	for col := 0; col <= width; col++ {
+		blank := true
+		for _, line := range lines {
+			if col < len(line) && line[col] != ' ' {
+				blank = false
+				break
+			}
+		}
 
-	// We use left-aligned operators as our signal to terminate operand accumulation for
-	// a given expression, so we iterate through the input from right to left, bottom to top
-	// to construct operands
-	for j := len(input[0]) - 1; j >= 0; j-- {
-		operand, powerOfTen := 0, 0
-		for i := operatorRow - 1; i >= 0; i-- {
-			operandChar := input[i][j]
-			if operandChar >= '0' && operandChar <= '9' {
-				digit, _ := strconv.Atoi(string(operandChar))
-				operand += digit * int(math.Pow10(powerOfTen))
-				powerOfTen++ // Move to the next digit place
+		if !blank && blockStart < 0 {
+			blockStart = col
+		} else if blank && blockStart >= 0 {
+			spans = append(spans, [2]int{blockStart, col})
+			blockStart = -1
+		}
+	}
+
+	return spans
+}
+
+// columnRange returns the characters of the line between the start and (exclusive) end
+// columns, padded with spaces if the line ends before them.
+func columnRange(line string, start, end int) string {
+	text := ""
+	if start < len(line) {
+		text = line[start:min(end, len(line))]
+	}
+	return text + strings.Repeat(" ", end-start-len(text))
+}
+
+// readRowWise reads a block's operands horizontally, one per row, skipping blank rows.
+func readRowWise(blk block) ([]int, error) {
+	var operands []int
+	for _, row := range blk.rows {
+		field := strings.TrimSpace(row)
+		if field == "" {
+			continue
+		}
+
+		operand, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid operand %q: %w", field, err)
+		}
+		operands = append(operands, operand)
+	}
+
+	return operands, nil
+}
+
+// readColumnWise reads a block's operands vertically, one per column from right to left, with the
+// most significant digit at the top. Columns that hold no digits are skipped.
+func readColumnWise(blk block) ([]int, error) {
+	var operands []int
+	for col := blk.end - blk.start - 1; col >= 0; col-- {
+		operand, hasDigits := 0, false
+		for _, row := range blk.rows {
+			switch char := row[col]; {
+			case char >= '0' && char <= '9':
+				operand = operand*10 + int(char-'0')
+				hasDigits = true
+			case char != ' ':
+				return nil, fmt.Errorf("invalid operand digit %q in column %d", char, blk.start+col+1)
 			}
 		}
-		currOperands = append(currOperands, operand)
 
-		// If we encounter a left-aligned operator, we can evaluate the aggregated expression
-		operatorChar := input[operatorRow][j]
-		if operatorChar == '*' || operatorChar == '+' {
-			total += evaluateExpression(currOperands, rune(operatorChar))
-			currOperands = currOperands[:0]
-			j-- // Skip column of spaces between operators
+		if hasDigits {
+			operands = append(operands, operand)
 		}
 	}
 
-	return total
+	return operands, nil
 }
 
-// getOperatorRowIndex returns the index of the row that contains the operators.
+// getOperatorRowIndex returns the index of the row that contains the operators, which is
+// the first row that does not start with an operand.
 func getOperatorRowIndex(input []string) int {
 	for i, line := range input {
-		if line[0] == '*' || line[0] == '+' {
+		parts := strings.Fields(line)
+		if len(parts) > 0 && (parts[0][0] < '0' || parts[0][0] > '9') {
 			return i
 		}
 	}
@@ -88,58 +230,76 @@ func getOperatorRowIndex(input []string) int {
 	return -1
 }
 
-// evaluateExpression evaluates the expression for the given operator and index across all operands.
-func evaluateExpression(operands []int, operator rune) int {
-	var result int
-	switch operator {
-	case '+':
-		result = 0
-		for _, operand := range operands {
-			result += operand
-		}
-	case '*':
-		result = 1
-		for _, operand := range operands {
-			result *= operand
-		}
+// parseOperator parses the operator in a block's part of the operator row, which starts at the given column.
+func parseOperator(text string, column int) (operator, error) {
+	symbol := strings.TrimSpace(text)
+	if symbol == "" {
+		return 0, fmt.Errorf("missing operator in column %d", column+1)
 	}
 
-	return result
+	op, ok := operatorTable[symbol]
+	if !ok {
+		column += strings.Index(text, symbol)
+		return 0, fmt.Errorf("unknown operator %q in column %d", symbol, column+1)
+	}
+	return op, nil
 }
 
-// parseOperands parses the operand rows from the input lines until it encounters an operator line.
-func parseOperands(input []string) [][]int {
-	var allOperands [][]int
-	for _, line := range input {
-		parts := strings.Fields(line)
-		if parts[0][0] == '*' || parts[0][0] == '+' {
-			break // We have encountered an operator, so we are done
+// evaluateExpression evaluates the expression for the given operator across all operands, applying it
+// from left to right, except for powers, which are applied from right to left (so 2 ^ 3 ^ 2 is 2 ^ 9).
+func evaluateExpression[T any](operands []int, op operator, arith checked.Arithmetic[T]) (T, error) {
+	if len(operands) == 0 {
+		var zero T
+		return zero, fmt.Errorf("expression has no operands")
+	}
+
+	if op == power {
+		result := arith.FromInt(operands[len(operands)-1])
+		var err error
+		for i := len(operands) - 2; i >= 0; i-- {
+			if result, err = arith.Pow(arith.FromInt(operands[i]), result); err != nil {
+				return result, err
+			}
 		}
+		return result, nil
+	}
 
-		var currOperands []int
-		for _, part := range parts {
-			num, _ := strconv.Atoi(part)
-			currOperands = append(currOperands, num)
+	result := arith.FromInt(operands[0])
+	var err error
+	for _, operand := range operands[1:] {
+		if result, err = applyOperator(op, result, arith.FromInt(operand), arith); err != nil {
+			return result, err
 		}
-		allOperands = append(allOperands, currOperands)
 	}
 
-	return allOperands
+	return result, nil
 }
 
-// parseOperators parses the operators from the end of the input lines.
-func parseOperators(input []string) []rune {
-	var operators []rune
-	for _, line := range input {
-		parts := strings.Fields(line)
-
-		// Iterate until we find the first operator line
-		if parts[0][0] == '*' || parts[0][0] == '+' {
-			for _, part := range parts {
-				operators = append(operators, rune(part[0]))
-			}
+// applyOperator applies a binary operator to a pair of values.
+func applyOperator[T any](op operator, a, b T, arith checked.Arithmetic[T]) (T, error) {
+	switch op {
+	case add:
+		return arith.Add(a, b)
+	case subtract:
+		return arith.Sub(a, b)
+	case multiply:
+		return arith.Mul(a, b)
+	case divide:
+		return arith.Div(a, b)
+	case maximum:
+		if arith.Cmp(a, b) >= 0 {
+			return a, nil
+		}
+		return b, nil
+	case minimum:
+		if arith.Cmp(a, b) <= 0 {
+			return a, nil
 		}
+		return b, nil
+	case power:
+		return arith.Pow(a, b)
 	}
 
-	return operators
+	var zero T
+	return zero, fmt.Errorf("unsupported operator %d", op)
 }
@@ -1,14 +1,19 @@
 package solutions
 
 import (
+	"aoc-2025/internal/checked"
 	"aoc-2025/internal/registry"
 	"fmt"
-	"slices"
+	"math/big"
+	"math/bits"
+	"strings"
 )
 
 // Manifold characters
 const start = 'S'
 const splitter = '^'
+const emptyCell = '.'
+const beamPath = '|'
 
 func init() {
 	registry.Register(7, 1, SolveDay7Part1)
@@ -16,104 +21,262 @@ func init() {
 }
 
 func SolveDay7Part1(input []string) string {
-	totalSplits := totalBeamSplits(input)
+	totalSplits, _, err := simulateManifold(input)
+	if err != nil {
+		return "failed to simulate manifold: " + err.Error()
+	}
 	return fmt.Sprintf("The beam is split %d times", totalSplits)
 }
 
 func SolveDay7Part2(input []string) string {
-	totalTimelines := totalTimelines(input)
-	return fmt.Sprintf("The original beam undergoes %d timelines", totalTimelines)
+	_, totalTimelines, err := simulateManifold(input)
+	if err != nil {
+		return "failed to simulate manifold: " + err.Error()
+	}
+	return fmt.Sprintf("The original beam undergoes %s timelines", totalTimelines)
 }
 
-// totalBeamSplits calculates the total number of beam splits that occur
-// as the beam traverses through the manifold represented by the input grid.
-func totalBeamSplits(input []string) int {
-	totalSplits := 0
+// bitset is a fixed-size set of column indices packed into 64-bit words.
+type bitset []uint64
 
-	// Establish initial beam location
-	beamLocs := make(map[int]int)
-	beamLocs[getBeamStartLocation(input)] = 1
-
-	// Splitters are located on every other row starting from the third row (index 2)
-	for i := 2; i < len(input); i += 2 {
-		splitterLocations := getSplitterLocations(input[i])
-		numSplits, newBeamLocs := findBeamSplitsAndNewBeamLocations(beamLocs, splitterLocations)
-		totalSplits += numSplits
-		beamLocs = newBeamLocs
+func newBitset(size int) bitset {
+	return make(bitset, (size+63)/64)
+}
+
+func (b bitset) set(i int) {
+	b[i/64] |= 1 << (i % 64)
+}
+
+func (b bitset) has(i int) bool {
+	return b[i/64]&(1<<(i%64)) != 0
+}
+
+func (b bitset) clear() {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// forEach calls visit with every index in the set, in increasing order.
+func (b bitset) forEach(visit func(int)) {
+	for w, word := range b {
+		for word != 0 {
+			visit(w*64 + bits.TrailingZeros64(word))
+			word &= word - 1 // Clear the lowest set bit
+		}
+	}
+}
+
+// manifold is the preprocessed form of the input grid, with the splitter columns of
+// each row stored as a bitset so that beams can be checked against them in constant time.
+type manifold struct {
+	width     int // Length of the longest row; shorter rows are treated as padded with empty cells
+	startRow  int
+	startCol  int
+	splitters []bitset
+}
+
+// parseManifold preprocesses the input grid into a splitter bitset per row, and locates
+// the beam's starting cell (row and column -1 if there is none).
+func parseManifold(input []string) manifold {
+	m := manifold{startRow: -1, startCol: -1, splitters: make([]bitset, len(input))}
+	for _, line := range input {
+		m.width = max(m.width, len(line))
+	}
+
+	for row, line := range input {
+		m.splitters[row] = newBitset(m.width)
+		for col, char := range line {
+			switch char {
+			case splitter:
+				m.splitters[row].set(col)
+			case start:
+				if m.startRow < 0 {
+					m.startRow, m.startCol = row, col
+				}
+			}
+		}
 	}
 
-	return totalSplits
+	return m
 }
 
-// totalTimelines calculates the total number of distinct beam timelines that result
-// from the beam traversing through the manifold represented by the input grid.
-func totalTimelines(input []string) int {
-	// Map from beam location to count of timelines at that location
-	beamLocs := make(map[int]int)
-	beamLocs[getBeamStartLocation(input)] = 1
+// simulateManifold traces the beam through the manifold, returning the number of splits and the
+// total number of timelines in decimal. Timelines double at every split, so they are counted with
+// overflow-checked int64 arithmetic, falling back to arbitrary precision if that overflows.
+// In verbose mode, the manifold is rendered once with the beam's path drawn in, from the trace
+// that produced the result.
+func simulateManifold(input []string) (int, string, error) {
+	m := parseManifold(input)
 
-	// Splitters are located on every other row starting from the third row (index 2)
-	for i := 2; i < len(input); i += 2 {
-		splitterLocations := getSplitterLocations(input[i])
-		_, beamLocs = findBeamSplitsAndNewBeamLocations(beamLocs, splitterLocations)
+	var totalSplits int
+	var render func() string
+	totalTimelines, err := checked.WithFallback(
+		func(arith checked.Int64) (int64, error) {
+			trace, timelines, err := traceAndCountTimelines(m, arith)
+			totalSplits, render = trace.splits, func() string { return renderManifold(input, trace) }
+			return timelines, err
+		},
+		func(arith checked.Big) (*big.Int, error) {
+			trace, timelines, err := traceAndCountTimelines(m, arith)
+			totalSplits, render = trace.splits, func() string { return renderManifold(input, trace) }
+			return timelines, err
+		},
+	)
+	if err != nil {
+		return 0, "", err
 	}
 
-	// Sum up all timelines across all final beam locations
-	totalTimelines := 0
-	for _, count := range beamLocs {
-		totalTimelines += count
+	if registry.Verbose() {
+		fmt.Print(render())
 	}
+	return totalSplits, totalTimelines, nil
+}
+
+// traceAndCountTimelines traces the beam through the manifold using the given arithmetic,
+// returning the trace along with the total number of timelines.
+func traceAndCountTimelines[T any](m manifold, arith checked.Arithmetic[T]) (beamTrace[T], T, error) {
+	trace, err := traceBeams(m, arith, registry.Verbose())
+	if err != nil {
+		var zero T
+		return trace, zero, err
+	}
+
+	totalTimelines, err := trace.totalTimelines(arith)
+	return trace, totalTimelines, err
+}
 
-	return totalTimelines
+// beamTrace records the path of the beam through the manifold, with timelines counted as T.
+type beamTrace[T any] struct {
+	startRow   int
+	splits     int
+	exited     T      // Timelines whose beam left through the left or right edge of the manifold
+	finalBeams bitset // Beam locations after the last row
+	finalCount []T    // Count of timelines at each final beam location, indexed by column
+	history    []beamRow[T]
 }
 
-// findBeamSplitsAndNewBeamLocations identifies the number of beam splits that occur
-// at the current row of the manifold and computes the new beam locations after the splits.
-func findBeamSplitsAndNewBeamLocations(beamLocations map[int]int, splitterLocations []int) (int, map[int]int) {
-	numSplits := 0
-	newBeamLocations := make(map[int]int)
+// beamRow is a snapshot of the beam locations and their timeline counts after passing a row,
+// kept only when the manifold is to be rendered.
+type beamRow[T any] struct {
+	cols   []int
+	counts []T
+}
 
-	// Retain beam locations that do not intersect with any splitter locations
-	for beamLoc := range beamLocations {
-		if !slices.Contains(splitterLocations, beamLoc) {
-			newBeamLocations[beamLoc] = beamLocations[beamLoc]
+// totalTimelines sums the timelines across all final beam locations, including those
+// whose beam left the manifold through either edge along the way.
+func (t beamTrace[T]) totalTimelines(arith checked.Arithmetic[T]) (T, error) {
+	total := t.exited
+	var err error
+	t.finalBeams.forEach(func(col int) {
+		if err == nil {
+			total, err = arith.Add(total, t.finalCount[col])
 		}
+	})
+
+	return total, err
+}
+
+// traceBeams follows the beam from its starting location down through every subsequent row
+// of the manifold, splitting it at each splitter it meets, and records the number of splits
+// and the timelines at each final beam location. Beam counts are kept in dense slices indexed
+// by column, alongside a bitset of the columns that currently hold a beam. If keepHistory is
+// true, a snapshot of the beams after every row is also recorded.
+func traceBeams[T any](m manifold, arith checked.Arithmetic[T], keepHistory bool) (beamTrace[T], error) {
+	trace := beamTrace[T]{startRow: m.startRow, exited: arith.Zero()}
+	if keepHistory {
+		trace.history = make([]beamRow[T], len(m.splitters))
 	}
 
-	// Check each splitter location to see if it intersects with any current beam locations
-	for _, splitLoc := range splitterLocations {
-		if count, exists := beamLocations[splitLoc]; exists {
-			// Split each timeline's beam into two new beams at adjacent locations
-			newBeamLocations[splitLoc-1] += count
-			newBeamLocations[splitLoc+1] += count
-			numSplits++
+	// Double buffer the beams of the current and next rows
+	beams, nextBeams := newBitset(m.width), newBitset(m.width)
+	counts, nextCounts := make([]T, m.width), make([]T, m.width)
+	record := func(row int) {
+		if keepHistory {
+			beams.forEach(func(col int) {
+				trace.history[row].cols = append(trace.history[row].cols, col)
+				trace.history[row].counts = append(trace.history[row].counts, counts[col])
+			})
 		}
 	}
 
-	return numSplits, newBeamLocations
-}
+	if m.startRow < 0 {
+		trace.finalBeams, trace.finalCount = beams, counts
+		return trace, nil // No beam enters the manifold
+	}
+
+	// Establish initial beam location
+	beams.set(m.startCol)
+	counts[m.startCol] = arith.FromInt(1)
+	record(m.startRow)
+
+	for row := m.startRow + 1; row < len(m.splitters); row++ {
+		nextBeams.clear()
+		var err error
 
-// getSplitterLocations locates all column indices marked by the splitter character
-// in the given row of the manifold.
-func getSplitterLocations(row string) []int {
-	var splitterLocations []int
-	for col, char := range row {
-		if char == splitter {
-			splitterLocations = append(splitterLocations, col)
+		// Add timelines to a beam location in the next row, starting it off if it is new
+		addBeam := func(col int, count T) {
+			if err != nil {
+				return
+			}
+			if col < 0 || col >= m.width {
+				trace.exited, err = arith.Add(trace.exited, count)
+			} else if !nextBeams.has(col) {
+				nextBeams.set(col)
+				nextCounts[col] = count
+			} else {
+				nextCounts[col], err = arith.Add(nextCounts[col], count)
+			}
 		}
+
+		beams.forEach(func(col int) {
+			if m.splitters[row].has(col) {
+				// Split each timeline's beam into two new beams at adjacent locations
+				addBeam(col-1, counts[col])
+				addBeam(col+1, counts[col])
+				trace.splits++
+			} else {
+				addBeam(col, counts[col])
+			}
+		})
+		if err != nil {
+			return trace, fmt.Errorf("row %d: %w", row, err)
+		}
+
+		beams, nextBeams = nextBeams, beams
+		counts, nextCounts = nextCounts, counts
+		record(row)
 	}
 
-	return splitterLocations
+	trace.finalBeams, trace.finalCount = beams, counts
+	return trace, nil
 }
 
-// getBeamStartLocation locates the starting column index marked by the starting
-// character in the first line of the input grid.
-func getBeamStartLocation(input []string) int {
-	for col, char := range input[0] {
-		if char == start {
-			return col
+// renderManifold draws the manifold with the beam's path marked by '|' in every cell it passes
+// through, followed beneath each row by the number of timelines at each beam location. The trace
+// must have been recorded with its history kept.
+func renderManifold[T any](input []string, trace beamTrace[T]) string {
+	var sb strings.Builder
+	for i, line := range input {
+		row := []byte(line)
+		beams := trace.history[i]
+		for _, col := range beams.cols {
+			if col < len(row) && row[col] == emptyCell {
+				row[col] = beamPath
+			}
+		}
+
+		sb.Write(row)
+		sb.WriteByte('\n')
+		if len(beams.cols) > 0 && i > trace.startRow {
+			sb.WriteString("  timelines:")
+			for j, col := range beams.cols {
+				fmt.Fprintf(&sb, " %d=%v", col, beams.counts[j])
+			}
+			sb.WriteByte('\n')
 		}
 	}
+	fmt.Fprintf(&sb, "  timelines exiting the sides: %v\n", trace.exited)
 
-	return -1
+	return sb.String()
 }
@@ -0,0 +1,159 @@
+package solutions
+
+import (
+	"aoc-2025/internal/checked"
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// wideManifold generates a manifold 10,000 columns wide and the given number of rows tall, with
+// the beam starting in the middle of the top row and a random tenth of the cells on every other
+// row below holding splitters.
+func wideManifold(height int) []string {
+	const width = 10000
+	rng := rand.New(rand.NewSource(7))
+
+	input := make([]string, height)
+	row := []byte(strings.Repeat(string(emptyCell), width))
+	row[width/2] = start
+	input[0] = string(row)
+	for i := 1; i < height; i++ {
+		row := []byte(strings.Repeat(string(emptyCell), width))
+		if i%2 == 0 {
+			for col := range row {
+				if rng.Intn(10) == 0 {
+					row[col] = splitter
+				}
+			}
+		}
+		input[i] = string(row)
+	}
+	return input
+}
+
+// traceBeamsWithMaps is the tracer that traceBeams replaced, kept as a baseline for comparison.
+// It holds the timelines at each beam location in a map and looks splitters up in a list of
+// their columns, returning the number of splits and the total number of timelines.
+func traceBeamsWithMaps[T any](input []string, arith checked.Arithmetic[T]) (int, T, error) {
+	numSplits, numExited := 0, arith.Zero()
+	var beamLocs map[int]T
+	for row, line := range input {
+		if beamLocs == nil {
+			// Skip the rows above the beam's starting location
+			if col := strings.IndexRune(line, start); col >= 0 {
+				beamLocs = map[int]T{col: arith.FromInt(1)}
+			}
+			continue
+		}
+
+		var splitterLocs []int
+		for col, char := range line {
+			if char == splitter {
+				splitterLocs = append(splitterLocs, col)
+			}
+		}
+
+		newBeamLocs := make(map[int]T)
+		for beamLoc, count := range beamLocs {
+			if !slices.Contains(splitterLocs, beamLoc) {
+				newBeamLocs[beamLoc] = count
+			}
+		}
+		for _, splitLoc := range splitterLocs {
+			count, exists := beamLocs[splitLoc]
+			if !exists {
+				continue
+			}
+			for _, newLoc := range [2]int{splitLoc - 1, splitLoc + 1} {
+				var err error
+				if newLoc < 0 || newLoc >= len(line) {
+					numExited, err = arith.Add(numExited, count)
+				} else {
+					total, exists := newBeamLocs[newLoc]
+					if !exists {
+						total = arith.Zero()
+					}
+					newBeamLocs[newLoc], err = arith.Add(total, count)
+				}
+				if err != nil {
+					return numSplits, numExited, fmt.Errorf("row %d: %w", row, err)
+				}
+			}
+			numSplits++
+		}
+		beamLocs = newBeamLocs
+	}
+
+	total := numExited
+	for _, count := range beamLocs {
+		var err error
+		if total, err = arith.Add(total, count); err != nil {
+			return numSplits, total, err
+		}
+	}
+	return numSplits, total, nil
+}
+
+func TestTraceBeamsMatchesMaps(t *testing.T) {
+	example := []string{
+		".......S.......", "...............", ".......^.......", "...............",
+		"......^.^......", "...............", ".....^.^.^.....", "...............",
+		"....^.^...^....", "...............", "...^.^...^.^...", "...............",
+		"..^...^.....^..", "...............", ".^.^.^.^.^...^.", "...............",
+	}
+	for name, input := range map[string][]string{"example": example, "wide": wideManifold(1000)} {
+		trace, err := traceBeams(parseManifold(input), checked.Big{}, false)
+		if err != nil {
+			t.Fatalf("%s: traceBeams: %v", name, err)
+		}
+		got, err := trace.totalTimelines(checked.Big{})
+		if err != nil {
+			t.Fatalf("%s: totalTimelines: %v", name, err)
+		}
+
+		wantSplits, want, err := traceBeamsWithMaps(input, checked.Big{})
+		if err != nil {
+			t.Fatalf("%s: traceBeamsWithMaps: %v", name, err)
+		}
+		if trace.splits != wantSplits || got.Cmp(want) != 0 {
+			t.Errorf("%s: got %d splits and %s timelines, want %d and %s", name, trace.splits, got, wantSplits, want)
+		}
+	}
+}
+
+func BenchmarkTraceBeams(b *testing.B) {
+	b.Run("Int64", func(b *testing.B) { benchmarkTraceBeams(b, checked.Int64{}) })
+	b.Run("Big", func(b *testing.B) { benchmarkTraceBeams(b, checked.Big{}) })
+}
+
+func BenchmarkTraceBeamsWithMaps(b *testing.B) {
+	b.Run("Int64", func(b *testing.B) { benchmarkTraceBeamsWithMaps(b, checked.Int64{}) })
+	b.Run("Big", func(b *testing.B) { benchmarkTraceBeamsWithMaps(b, checked.Big{}) })
+}
+
+// benchmarkTraceBeams includes parsing the manifold, since the baseline tracer scans each row
+// of the input for splitters as it goes.
+func benchmarkTraceBeams[T any](b *testing.B, arith checked.Arithmetic[T]) {
+	input := wideManifold(1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		if _, err := traceBeams(parseManifold(input), arith, false); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchmarkTraceBeamsWithMaps[T any](b *testing.B, arith checked.Arithmetic[T]) {
+	input := wideManifold(1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		if _, _, err := traceBeamsWithMaps(input, arith); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -2,17 +2,47 @@ package solutions
 
 import (
 	"aoc-2025/internal/registry"
+	"aoc-2025/internal/spatial"
+	"cmp"
 	"container/heap"
 	"fmt"
 	"math"
+	"os"
+	"path/filepath"
 	"slices"
+	"strings"
 )
 
-// Connection represents a weighted edge between two junctions in 3D space.
+// Connection represents a weighted edge between two junctions in 3D space. The endpoints are
+// ordered so that from is lexicographically smaller than to. Once the connection has been made,
+// joined records whether it merged two previously separate circuits.
 type Connection struct {
 	from     [3]int
 	to       [3]int
 	distance int
+	joined   bool
+}
+
+// newConnection creates a connection between two junctions with its endpoints in canonical order.
+func newConnection(a, b [3]int) Connection {
+	if slices.Compare(a[:], b[:]) > 0 {
+		a, b = b, a
+	}
+	dx, dy, dz := a[0]-b[0], a[1]-b[1], a[2]-b[2]
+	distSquared := dx*dx + dy*dy + dz*dz // Use squared distance to avoid float operations
+	return Connection{from: a, to: b, distance: distSquared}
+}
+
+// compareConnections orders connections by distance, breaking ties by comparing endpoints
+// lexicographically, so that connections of equal length are always made in the same order.
+func compareConnections(a, b Connection) int {
+	if a.distance != b.distance {
+		return cmp.Compare(a.distance, b.distance)
+	}
+	if c := slices.Compare(a.from[:], b.from[:]); c != 0 {
+		return c
+	}
+	return slices.Compare(a.to[:], b.to[:])
 }
 
 // PriorityQueue implements a min-heap for connections between 3D positions based on distance.
@@ -20,7 +50,7 @@ type PriorityQueue []Connection
 
 func (pq PriorityQueue) Len() int { return len(pq) }
 func (pq PriorityQueue) Less(i, j int) bool {
-	return pq[i].distance < pq[j].distance
+	return compareConnections(pq[i], pq[j]) < 0
 }
 func (pq PriorityQueue) Swap(i, j int) {
 	pq[i], pq[j] = pq[j], pq[i]
@@ -67,14 +97,27 @@ func (uf *UnionFind) Union(a, b [3]int) bool {
 func init() {
 	registry.Register(8, 1, SolveDay8Part1)
 	registry.Register(8, 2, SolveDay8Part2)
+
+	registry.RegisterIntParam(8, 1, "connections", 1000, "number of shortest connections to make")
+	registry.RegisterIntParam(8, 1, "circuits", 3, "number of largest circuits to multiply the sizes of")
 }
 
 func SolveDay8Part1(input []string) string {
+	maxConnections := registry.IntParam(8, 1, "connections")
+	if maxConnections < 0 {
+		return fmt.Sprintf("invalid number of connections: must not be negative, got %d", maxConnections)
+	}
+	numCircuits := registry.IntParam(8, 1, "circuits")
+	if numCircuits < 0 {
+		return fmt.Sprintf("invalid number of circuits: must not be negative, got %d", numCircuits)
+	}
+
 	positions := parseJunctionPositions(input)
-	maxConnections := 1000
-	_, connections := makeConnections(positions, maxConnections)
-	numCircuits := 3
-	largestCircuits := getKLargestCircuits(connections, numCircuits)
+	_, components, connections := connectJunctions(positions, maxConnections)
+	if err := exportCircuits(registry.ExportPath(), connections, components); err != nil {
+		return "failed to export circuits: " + err.Error()
+	}
+	largestCircuits := getKLargestCircuits(components, numCircuits)
 	product := circuitSizeProduct(largestCircuits)
 	return fmt.Sprintf("The product of the sizes of the %d largest circuits is %d", numCircuits, product)
 }
@@ -82,7 +125,11 @@ func SolveDay8Part1(input []string) string {
 func SolveDay8Part2(input []string) string {
 	positions := parseJunctionPositions(input)
 	maxConnections := math.MaxInt // No limit on connections this time - build full spanning tree
-	xCoordProduct, _ := makeConnections(positions, maxConnections)
+	xCoordProduct, components, connections := connectJunctions(positions, maxConnections)
+	spanningTree := slices.DeleteFunc(connections, func(conn Connection) bool { return !conn.joined })
+	if err := exportCircuits(registry.ExportPath(), spanningTree, components); err != nil {
+		return "failed to export circuits: " + err.Error()
+	}
 	return fmt.Sprintf("The product of the x-coordinates of the last two connected junctions is %d", xCoordProduct)
 }
 
@@ -97,7 +144,8 @@ func circuitSizeProduct(circuits [][][3]int) int {
 }
 
 // getKLargestCircuits identifies the k largest circuits (connected components)
-// in the junction graph represented as an adjacency list.
+// in the junction graph represented as an adjacency list. Circuits of equal size
+// are ordered by their lexicographically smallest junction.
 func getKLargestCircuits(components map[[3]int][][3]int, k int) [][][3]int {
 	var circuits [][][3]int
 	for _, nodes := range components {
@@ -106,7 +154,10 @@ func getKLargestCircuits(components map[[3]int][][3]int, k int) [][][3]int {
 
 	// Sort descending by size
 	slices.SortFunc(circuits, func(a, b [][3]int) int {
-		return len(b) - len(a)
+		if len(a) != len(b) {
+			return len(b) - len(a)
+		}
+		return compareJunctions(slices.MinFunc(a, compareJunctions), slices.MinFunc(b, compareJunctions))
 	})
 
 	// Trim to k largest if necessary
@@ -116,33 +167,165 @@ func getKLargestCircuits(components map[[3]int][][3]int, k int) [][][3]int {
 	return circuits
 }
 
+// connectJunctions connects junctions using either the spatial index (the default) or, when the
+// "reference" variant is selected, the exhaustive pairwise implementation in makeConnections.
+func connectJunctions(positions [][3]int, maxConnections int) (int, map[[3]int][][3]int, []Connection) {
+	if registry.Variant() == "reference" {
+		return makeConnections(positions, maxConnections)
+	}
+	return makeConnectionsSpatial(positions, maxConnections)
+}
+
+// makeConnectionsSpatial produces the same result as makeConnections without generating every
+// pairwise connection. Junctions are indexed in a k-d tree, and when the connections are limited,
+// only the shortest ones (at most every junction pair) are gathered through radius queries.
+// Without a limit, the full minimum spanning tree is built with Borůvka's algorithm, whose heaviest
+// edge is the last one Kruskal's algorithm would have added.
+func makeConnectionsSpatial(positions [][3]int, maxConnections int) (int, map[[3]int][][3]int, []Connection) {
+	// Index junctions in lexicographic order, so that breaking ties by index agrees with compareConnections
+	sorted := slices.Clone(positions)
+	slices.SortFunc(sorted, compareJunctions)
+	tree := spatial.NewKDTree(sorted)
+	numPairs := len(sorted) * (len(sorted) - 1) / 2
+
+	var connections []Connection
+	if maxConnections == math.MaxInt {
+		connections = minimumSpanningTree(sorted, tree)
+	} else {
+		connections = nearestConnections(sorted, tree, min(maxConnections, numPairs))
+	}
+
+	// Connect junctions in order of increasing distance, as Kruskal's algorithm would
+	uf := newUnionFind(positions)
+	var xCoordProduct int
+	for i, conn := range connections {
+		if uf.Union(conn.from, conn.to) {
+			xCoordProduct = conn.from[0] * conn.to[0]
+			connections[i].joined = true
+		}
+	}
+
+	// Build component graph as adjacency list
+	components := make(map[[3]int][][3]int)
+	for _, pos := range positions {
+		root := uf.Find(pos)
+		components[root] = append(components[root], pos)
+	}
+
+	return xCoordProduct, components, connections
+}
+
+// nearestConnections finds the k shortest connections between junctions, sorted as by compareConnections.
+// The search radius is repeatedly doubled until it encloses at least k junction pairs.
+func nearestConnections(positions [][3]int, tree *spatial.KDTree, k int) []Connection {
+	var connections []Connection
+	for radiusSquared := 1; len(connections) < k; radiusSquared *= 4 {
+		connections = connections[:0]
+		for i, pos := range positions {
+			tree.WithinRadius(pos, radiusSquared, func(j, distSquared int) {
+				if j > i { // Count each pair only once
+					connections = append(connections, newConnection(pos, positions[j]))
+				}
+			})
+		}
+	}
+
+	slices.SortFunc(connections, compareConnections)
+	return connections[:k]
+}
+
+// minimumSpanningTree builds the minimum spanning tree of the junctions using Borůvka's algorithm,
+// returning its connections sorted as by compareConnections. In each round, every circuit finds its
+// shortest connection to a junction in a different circuit using the k-d tree, and all of these are
+// added. Junctions must be sorted lexicographically, so that breaking ties by junction index gives
+// every circuit the same ordering of connections as compareConnections.
+func minimumSpanningTree(positions [][3]int, tree *spatial.KDTree) []Connection {
+	type edge struct {
+		from, to, distance int
+	}
+	less := func(a, b edge) bool {
+		if a.distance != b.distance {
+			return a.distance < b.distance
+		}
+		if min(a.from, a.to) != min(b.from, b.to) {
+			return min(a.from, a.to) < min(b.from, b.to)
+		}
+		return max(a.from, a.to) < max(b.from, b.to)
+	}
+
+	// Track circuits by the index of a representative junction
+	parent := make([]int, len(positions))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(x int) int {
+		if parent[x] != x {
+			parent[x] = find(parent[x])
+		}
+		return parent[x]
+	}
+
+	var connections []Connection
+	labels := make([]int, len(positions))
+	for numCircuits := len(positions); numCircuits > 1; {
+		for i := range labels {
+			labels[i] = find(i)
+		}
+		tree.SetLabels(labels)
+
+		// Find the shortest outgoing connection of every circuit
+		shortest := make(map[int]edge)
+		for i, pos := range positions {
+			j, distSquared, ok := tree.NearestWithDifferentLabel(pos, labels[i])
+			if !ok {
+				continue
+			}
+			candidate := edge{i, j, distSquared}
+			if curr, exists := shortest[labels[i]]; !exists || less(candidate, curr) {
+				shortest[labels[i]] = candidate
+			}
+		}
+
+		for _, e := range shortest {
+			rootA, rootB := find(e.from), find(e.to)
+			if rootA != rootB {
+				parent[rootB] = rootA
+				numCircuits--
+				connections = append(connections, newConnection(positions[e.from], positions[e.to]))
+			}
+		}
+	}
+
+	slices.SortFunc(connections, compareConnections)
+	return connections
+}
+
 // makeConnections constructs a graph of junction connections using Kruskal's minimum
 // spanning tree algorithm, subject to the specified limit on the number of connections.
-// Returns the product of the x-coordinates of the last connected junctions as well as the
-// adjacency list of the component graph.
-func makeConnections(positions [][3]int, maxConnections int) (int, map[[3]int][][3]int) {
+// Returns the product of the x-coordinates of the last connected junctions, the adjacency list
+// of the component graph, and the connections that were made in order.
+func makeConnections(positions [][3]int, maxConnections int) (int, map[[3]int][][3]int, []Connection) {
 	// Build a min-heap of all possible pairwise connections between junctions by distance
 	pq := &PriorityQueue{}
 	heap.Init(pq)
 	for i, posA := range positions {
 		for j := i + 1; j < len(positions); j++ {
-			posB := positions[j]
-			dx, dy, dz := posA[0]-posB[0], posA[1]-posB[1], posA[2]-posB[2]
-			distSquared := dx*dx + dy*dy + dz*dz // Use squared distance to avoid float operations
-			heap.Push(pq, Connection{posA, posB, distSquared})
+			heap.Push(pq, newConnection(posA, positions[j]))
 		}
 	}
 
 	// Connect junctions using union-find until reaching the max allowed connections
 	uf := newUnionFind(positions)
-	numConnections := 0
+	var connections []Connection
 	var xCoordProduct int
-	for pq.Len() > 0 && numConnections < maxConnections {
+	for pq.Len() > 0 && len(connections) < maxConnections {
 		conn := heap.Pop(pq).(Connection)
 		if uf.Union(conn.from, conn.to) {
 			xCoordProduct = conn.from[0] * conn.to[0]
+			conn.joined = true
 		}
-		numConnections++
+		connections = append(connections, conn)
 	}
 
 	// Build component graph as adjacency list
@@ -152,7 +335,72 @@ func makeConnections(positions [][3]int, maxConnections int) (int, map[[3]int][]
 		components[root] = append(components[root], pos)
 	}
 
-	return xCoordProduct, components
+	return xCoordProduct, components, connections
+}
+
+// compareJunctions orders junction positions lexicographically by coordinates.
+func compareJunctions(a, b [3]int) int {
+	return slices.Compare(a[:], b[:])
+}
+
+// exportCircuits writes the connections that were made and the resulting circuits to the file at
+// path, in CSV or Graphviz DOT format depending on the file extension. Circuits are numbered from
+// largest to smallest. Nothing is written if path is empty.
+func exportCircuits(path string, connections []Connection, components map[[3]int][][3]int) error {
+	if path == "" {
+		return nil
+	}
+
+	circuits := getKLargestCircuits(components, len(components))
+	circuitOf := make(map[[3]int]int)
+	for i, circuit := range circuits {
+		for _, pos := range circuit {
+			circuitOf[pos] = i
+		}
+	}
+	junctionID := func(pos [3]int) string {
+		return fmt.Sprintf("%d,%d,%d", pos[0], pos[1], pos[2])
+	}
+
+	var sb strings.Builder
+	switch ext := filepath.Ext(path); ext {
+	case ".csv":
+		// Junction rows leave the second endpoint, distance and joined columns empty
+		sb.WriteString("record,x1,y1,z1,x2,y2,z2,distance_squared,joined,circuit\n")
+		for i, circuit := range circuits {
+			for _, pos := range circuit {
+				fmt.Fprintf(&sb, "junction,%s,,,,,,%d\n", junctionID(pos), i)
+			}
+		}
+		for _, conn := range connections {
+			fmt.Fprintf(&sb, "connection,%s,%s,%d,%t,%d\n",
+				junctionID(conn.from), junctionID(conn.to), conn.distance, conn.joined, circuitOf[conn.from])
+		}
+	case ".dot":
+		// Connections that did not join separate circuits are drawn dashed
+		sb.WriteString("graph circuits {\n")
+		for i, circuit := range circuits {
+			fmt.Fprintf(&sb, "  subgraph cluster_%d {\n", i)
+			fmt.Fprintf(&sb, "    label=\"circuit %d (%d junctions)\";\n", i, len(circuit))
+			for _, pos := range circuit {
+				fmt.Fprintf(&sb, "    \"%s\";\n", junctionID(pos))
+			}
+			sb.WriteString("  }\n")
+		}
+		for _, conn := range connections {
+			style := "solid"
+			if !conn.joined {
+				style = "dashed"
+			}
+			fmt.Fprintf(&sb, "  \"%s\" -- \"%s\" [label=\"%d\", style=%s];\n",
+				junctionID(conn.from), junctionID(conn.to), conn.distance, style)
+		}
+		sb.WriteString("}\n")
+	default:
+		return fmt.Errorf("unsupported export format %q (expected .csv or .dot)", ext)
+	}
+
+	return os.WriteFile(path, []byte(sb.String()), 0o644)
 }
 
 // parseJunctionPositions parses a list of strings representing 3D coordinates
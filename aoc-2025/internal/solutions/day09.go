@@ -1,18 +1,13 @@
 package solutions
 
 import (
+	"aoc-2025/internal/polygon"
 	"aoc-2025/internal/registry"
+	"aoc-2025/internal/svg"
 	"fmt"
+	"slices"
 )
 
-type Point struct {
-	x, y int
-}
-
-type Rectangle struct {
-	minX, minY, maxX, maxY int
-}
-
 func init() {
 	registry.Register(9, 1, SolveDay9Part1)
 	registry.Register(9, 2, SolveDay9Part2)
@@ -26,7 +21,24 @@ func SolveDay9Part1(input []string) string {
 
 func SolveDay9Part2(input []string) string {
 	redTiles := parseTileCoordinates(input)
-	maxRectangleArea := maxInscribedRectangleArea(redTiles)
+	tilePolygon, err := polygon.NewRectilinear(redTiles)
+	if err != nil {
+		return "invalid red tile polygon: " + err.Error()
+	}
+
+	// Check containment in constant time using a compressed grid, unless the reference is requested
+	isInside := newCompressedPolygon(redTiles).containsRectangle
+	if registry.Variant() == "reference" {
+		isInside = tilePolygon.ContainsRectangle
+	}
+
+	maxRectangleArea, bestRect := maxInscribedRectangleArea(redTiles, isInside)
+	if path := registry.RenderPath(); path != "" {
+		if err := renderTiles(redTiles, bestRect).WriteFile(path); err != nil {
+			return "failed to render tiles: " + err.Error()
+		}
+	}
+
 	return fmt.Sprintf("The largest inscribed rectangle area using two red tiles as opposite corners is %d", maxRectangleArea)
 }
 
@@ -54,8 +66,11 @@ func maxRectangleArea(coords [][2]int) int {
 
 // maxInscribedRectangleArea finds the largest rectangle inscribed within the polygon formed
 // by connecting red tiles (with green tiles), where two opposite corners must be red tiles.
-func maxInscribedRectangleArea(redTiles [][2]int) int {
+// The isInside function decides whether a candidate rectangle lies within the polygon.
+// Returns the largest area along with the rectangle achieving it.
+func maxInscribedRectangleArea(redTiles [][2]int, isInside func(polygon.Rectangle) bool) (int, polygon.Rectangle) {
 	maxArea := 0
+	var bestRect polygon.Rectangle
 
 	// Check all pairs of red tiles as potential rectangle corners
 	for i := range redTiles {
@@ -66,67 +81,145 @@ func maxInscribedRectangleArea(redTiles [][2]int) int {
 			minX, maxX := min(x1, x2), max(x1, x2)
 			minY, maxY := min(y1, y2), max(y1, y2)
 
-			rect := Rectangle{minX, minY, maxX, maxY}
+			rect := polygon.Rectangle{MinX: minX, MinY: minY, MaxX: maxX, MaxY: maxY}
 			potentialArea := (maxX - minX + 1) * (maxY - minY + 1)
 			if potentialArea <= maxArea {
 				continue // No need to check smaller areas
 			}
 
-			if isRectangleInsidePolygon(rect, redTiles) {
+			if isInside(rect) {
 				maxArea = potentialArea
+				bestRect = rect
 			}
 		}
 	}
 
-	return maxArea
+	return maxArea, bestRect
 }
 
-// isRectangleInsidePolygon checks if an axis-aligned rectangle is fully inside the polygon formed
-// by connecting the red tiles (with green tiles).
-func isRectangleInsidePolygon(rect Rectangle, polygon [][2]int) bool {
-	// Check that no polygon edge crosses through the rectangle interior
-	for i := range polygon {
-		j := (i + 1) % len(polygon) // Next vertex, wrapping around
-		start := Point{polygon[i][0], polygon[i][1]}
-		end := Point{polygon[j][0], polygon[j][1]}
+// renderTiles draws the polygon formed by the red tiles as an SVG document, with each tile
+// occupying a unit square centred on its coordinates. The green tiles inside are shaded, the
+// green boundary is outlined, the red tiles are marked at each vertex, and the given rectangle
+// is highlighted.
+func renderTiles(redTiles [][2]int, highlight polygon.Rectangle) *svg.Document {
+	doc := svg.New()
 
-		if isSegmentIntersectingRectangle(start, end, rect) {
-			return false
-		}
+	points := make([][2]float64, len(redTiles))
+	for i, tile := range redTiles {
+		points[i] = [2]float64{float64(tile[0]), float64(tile[1])}
+	}
+	doc.Polygon(points, svg.Style{Fill: "#c8e6c9", Stroke: "#2e7d32", StrokeWidth: 2})
+
+	doc.Rect(float64(highlight.MinX)-0.5, float64(highlight.MinY)-0.5,
+		float64(highlight.MaxX-highlight.MinX+1), float64(highlight.MaxY-highlight.MinY+1),
+		svg.Style{Fill: "#1e88e5", Stroke: "#0d47a1", StrokeWidth: 2, Opacity: 0.5})
+
+	// Size the red tile markers relative to the whole drawing so they stay visible at any scale
+	radius := max(doc.Extent()/300, 0.3)
+	for _, p := range points {
+		doc.Circle(p[0], p[1], radius, svg.Style{Fill: "#d32f2f"})
 	}
 
-	return true
+	return doc
 }
 
-// isSegmentIntersectingRectangle checks if an axis-aligned line segment crosses
-// through the interior of a rectangle.
-func isSegmentIntersectingRectangle(start, end Point, rect Rectangle) bool {
-	// Terminate early if both segment endpoints are outside rectangle bounds
-	if (start.x < rect.minX && end.x < rect.minX) || (start.x > rect.maxX && end.x > rect.maxX) ||
-		(start.y < rect.minY && end.y < rect.minY) || (start.y > rect.maxY && end.y > rect.maxY) {
-		return false
+// compressedPolygon is a coordinate-compressed grid over the tiles of a rectilinear polygon.
+// Along each axis, every distinct vertex coordinate gets its own row or column, and so does
+// each open interval between consecutive coordinates, together with one interval of padding
+// on either side. Every tile mapped to the same compressed cell is therefore either inside
+// the polygon (including its boundary) or outside it.
+type compressedPolygon struct {
+	xs, ys        []int   // Sorted distinct vertex coordinates
+	outsidePrefix [][]int // outsidePrefix[i][j] counts outside cells with row < i and column < j
+}
+
+// newCompressedPolygon builds the compressed grid for the polygon, marking its boundary and
+// then flood filling from the padding to find every cell outside of it.
+func newCompressedPolygon(polygon [][2]int) *compressedPolygon {
+	c := &compressedPolygon{}
+	for _, vertex := range polygon {
+		c.xs = append(c.xs, vertex[0])
+		c.ys = append(c.ys, vertex[1])
+	}
+	slices.Sort(c.xs)
+	slices.Sort(c.ys)
+	c.xs, c.ys = slices.Compact(c.xs), slices.Compact(c.ys)
+
+	// Columns index compressed x coordinates and rows index compressed y coordinates
+	cols, rows := 2*len(c.xs)+1, 2*len(c.ys)+1
+	boundary := make([][]bool, rows)
+	for i := range boundary {
+		boundary[i] = make([]bool, cols)
+	}
+	for i := range polygon {
+		j := (i + 1) % len(polygon) // Next vertex, wrapping around
+		startCol, endCol := c.compressX(polygon[i][0]), c.compressX(polygon[j][0])
+		startRow, endRow := c.compressY(polygon[i][1]), c.compressY(polygon[j][1])
+		for row := min(startRow, endRow); row <= max(startRow, endRow); row++ {
+			for col := min(startCol, endCol); col <= max(startCol, endCol); col++ {
+				boundary[row][col] = true
+			}
+		}
 	}
 
-	// Check axis-aligned segments
-	if start.x == end.x {
-		// Vertical segment is problematic if strictly inside rectangle's x-range
-		if start.x > rect.minX && start.x < rect.maxX {
-			minSeg, maxSeg := min(start.y, end.y), max(start.y, end.y)
-			if minSeg < rect.maxY && maxSeg > rect.minY {
-				return true
+	// Flood fill the outside of the polygon, starting from the padding in the corner
+	outside := make([][]bool, rows)
+	for i := range outside {
+		outside[i] = make([]bool, cols)
+	}
+	outside[0][0] = true
+	stack := [][2]int{{0, 0}}
+	for len(stack) > 0 {
+		cell := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, dir := range [4][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+			nr, nc := cell[0]+dir[0], cell[1]+dir[1]
+			if nr >= 0 && nr < rows && nc >= 0 && nc < cols && !boundary[nr][nc] && !outside[nr][nc] {
+				outside[nr][nc] = true
+				stack = append(stack, [2]int{nr, nc})
 			}
 		}
-	} else if start.y == end.y {
-		// Horizontal segment is problematic if strictly inside rectangle's y-range
-		if start.y > rect.minY && start.y < rect.maxY {
-			minSeg, maxSeg := min(start.x, end.x), max(start.x, end.x)
-			if minSeg < rect.maxX && maxSeg > rect.minX {
-				return true
+	}
+
+	// Accumulate 2D prefix sums of outside cells
+	c.outsidePrefix = make([][]int, rows+1)
+	c.outsidePrefix[0] = make([]int, cols+1)
+	for row := range rows {
+		c.outsidePrefix[row+1] = make([]int, cols+1)
+		for col := range cols {
+			count := 0
+			if outside[row][col] {
+				count = 1
 			}
+			c.outsidePrefix[row+1][col+1] = count + c.outsidePrefix[row][col+1] +
+				c.outsidePrefix[row+1][col] - c.outsidePrefix[row][col]
 		}
 	}
 
-	return false
+	return c
+}
+
+// compressX maps a vertex x coordinate to its compressed column.
+func (c *compressedPolygon) compressX(x int) int {
+	i, _ := slices.BinarySearch(c.xs, x)
+	return 2*i + 1
+}
+
+// compressY maps a vertex y coordinate to its compressed row.
+func (c *compressedPolygon) compressY(y int) int {
+	i, _ := slices.BinarySearch(c.ys, y)
+	return 2*i + 1
+}
+
+// containsRectangle checks whether every tile of a rectangle whose corners lie on vertex
+// coordinates is inside the polygon, by counting the outside cells it covers in constant time
+// (plus the logarithmic lookup of its compressed corners).
+func (c *compressedPolygon) containsRectangle(rect polygon.Rectangle) bool {
+	minCol, maxCol := c.compressX(rect.MinX), c.compressX(rect.MaxX)
+	minRow, maxRow := c.compressY(rect.MinY), c.compressY(rect.MaxY)
+	p := c.outsidePrefix
+	numOutside := p[maxRow+1][maxCol+1] - p[minRow][maxCol+1] - p[maxRow+1][minCol] + p[minRow][minCol]
+	return numOutside == 0
 }
 
 // parseTileCoordinates parses input lines in "x,y" format into coordinate pairs.
@@ -0,0 +1,43 @@
+package solutions
+
+import (
+	"aoc-2025/internal/polygon"
+	"aoc-2025/internal/polygon/polygontest"
+	"math/rand"
+	"testing"
+)
+
+// TestCompressedPolygonContainsRectangle checks the compressed grid against the reference
+// polygon check and against the rasterized polygon, for all rectangles with red tiles at
+// opposite corners, as in part 2. Neighbouring vertex coordinates are often adjacent, which
+// leaves the compressed cells between them holding no tiles at all.
+func TestCompressedPolygonContainsRectangle(t *testing.T) {
+	rng := rand.New(rand.NewSource(9))
+	for _, size := range []int{6, 12, 40} {
+		for range 200 {
+			redTiles := polygontest.Random(rng, size)
+			tilePolygon, err := polygon.NewRectilinear(redTiles)
+			if err != nil {
+				t.Fatalf("NewRectilinear(%v): %v", redTiles, err)
+			}
+			compressed := newCompressedPolygon(redTiles)
+			raster := polygontest.Rasterize(redTiles)
+
+			for _, a := range redTiles {
+				for _, b := range redTiles {
+					rect := polygon.Rectangle{
+						MinX: min(a[0], b[0]), MinY: min(a[1], b[1]),
+						MaxX: max(a[0], b[0]), MaxY: max(a[1], b[1]),
+					}
+					want := raster.ContainsRectangle(rect)
+					if got := compressed.containsRectangle(rect); got != want {
+						t.Fatalf("red tiles %v: containsRectangle(%v) = %v, want %v", redTiles, rect, got, want)
+					}
+					if got := tilePolygon.ContainsRectangle(rect); got != want {
+						t.Fatalf("red tiles %v: ContainsRectangle(%v) = %v, want %v", redTiles, rect, got, want)
+					}
+				}
+			}
+		}
+	}
+}
@@ -1,117 +1,449 @@
 package solutions
 
 import (
+	"aoc-2025/internal/checked"
 	"aoc-2025/internal/registry"
 	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 )
 
-// Relevant device names
+// Default device names
 const youDevice = "you"
 const svrDevice = "svr"
 const targetDevice = "out"
 const dacDevice = "dac"
 const fftDevice = "fft"
 
+// Maximum number of required waypoints, each of which takes a bit of the visited set
+const maxWaypoints = 64
+
 func init() {
 	registry.Register(11, 1, SolveDay11Part1)
 	registry.Register(11, 2, SolveDay11Part2)
+
+	registry.RegisterStringParam(11, 1, "start", youDevice, "device that paths start from")
+	registry.RegisterStringParam(11, 2, "start", svrDevice, "device that paths start from")
+	registry.RegisterStringParam(11, 1, "waypoints", "", "comma-separated devices every path must visit")
+	registry.RegisterStringParam(11, 2, "waypoints", dacDevice+","+fftDevice,
+		"comma-separated devices every path must visit")
+	for part := 1; part <= 2; part++ {
+		registry.RegisterStringParam(11, part, "target", targetDevice, "device that paths end at")
+		registry.RegisterStringParam(11, part, "forbidden", "", "comma-separated devices no path may visit")
+		registry.RegisterIntParam(11, part, "paths", 0, "number of paths to list alongside the count")
+	}
 }
 
 func SolveDay11Part1(input []string) string {
-	connections := parseDeviceConnections(input)
-	numPaths := numPaths(youDevice, targetDevice, connections)
-	return fmt.Sprintf("The number of distinct paths from %s to %s is %d", youDevice, targetDevice, numPaths)
+	return solveDay11(input, 1)
 }
 
 func SolveDay11Part2(input []string) string {
-	connections := parseDeviceConnections(input)
-	numPaths := numPathsWithDACAndFFT(svrDevice, targetDevice, connections)
-	return fmt.Sprintf("The number of distinct paths from %s to %s is %d", svrDevice, targetDevice, numPaths)
+	return solveDay11(input, 2)
+}
+
+// solveDay11 counts the paths through the device network between the part's start and target devices
+// that visit every waypoint and no forbidden device, listing some of them if requested.
+func solveDay11(input []string, part int) string {
+	start, target := registry.StringParam(11, part, "start"), registry.StringParam(11, part, "target")
+	waypoints := parseDeviceList(registry.StringParam(11, part, "waypoints"))
+	forbidden := parseDeviceList(registry.StringParam(11, part, "forbidden"))
+
+	connections, err := parseDeviceConnections(input)
+	if err != nil {
+		return "invalid device network: " + err.Error()
+	}
+	if err := validateDeviceNetwork(connections, start, target); err != nil {
+		return "invalid device network: " + err.Error()
+	}
+	for _, device := range slices.Concat(waypoints, forbidden) {
+		if _, exists := connections[device]; !exists && device != target && device != targetDevice {
+			return fmt.Sprintf("invalid device network: unknown device %s", device)
+		}
+	}
+	if len(waypoints) > maxWaypoints {
+		return fmt.Sprintf("too many waypoints: %d (at most %d)", len(waypoints), maxWaypoints)
+	}
+
+	// Path counts grow exponentially with the depth of the network, so fall back to
+	// arbitrary precision if they overflow
+	pathCount, err := checked.WithFallback(
+		func(arith checked.Int64) (int64, error) {
+			return newPathCounter(connections, target, waypoints, forbidden, arith).count(start, 0)
+		},
+		func(arith checked.Big) (*big.Int, error) {
+			return newPathCounter(connections, target, waypoints, forbidden, arith).count(start, 0)
+		},
+	)
+	if err != nil {
+		return "failed to count paths: " + err.Error()
+	}
+
+	// Listing and exporting paths only needs to know which counts are non-zero, so use arbitrary
+	// precision rather than handling overflow
+	counter := newPathCounter(connections, target, waypoints, forbidden, checked.Big{})
+	if limit := registry.IntParam(11, part, "paths"); limit > 0 {
+		for _, path := range enumeratePaths(counter, start, limit) {
+			fmt.Println(strings.Join(path, " -> "))
+		}
+	}
+	if err := exportDeviceNetwork(registry.ExportPath(), connections, start, counter); err != nil {
+		return "failed to export device network: " + err.Error()
+	}
+	return fmt.Sprintf("The number of distinct paths from %s to %s is %s", start, target, pathCount)
+}
+
+// pathCounter counts the paths from devices to the target device in a network of devices
+// represented as a DAG, which visit every required waypoint, in any order, and avoid every
+// forbidden device. The waypoints visited so far are tracked as a bitmask.
+type pathCounter[T any] struct {
+	connections map[string][]string
+	target      string
+	waypoints   map[string]uint64 // Bit of each waypoint in the visited set
+	allVisited  uint64
+	forbidden   map[string]bool
+	arith       checked.Arithmetic[T]
+	memo        map[pathState]T
+}
+
+// pathState is a device reached on a path along with the set of waypoints visited before it.
+type pathState struct {
+	device  string
+	visited uint64
+}
+
+// newPathCounter creates a path counter with the given waypoints, of which there may be at most
+// maxWaypoints, and forbidden devices, counting with the given arithmetic.
+func newPathCounter[T any](connections map[string][]string, target string, waypoints, forbidden []string,
+	arith checked.Arithmetic[T]) *pathCounter[T] {
+	pc := &pathCounter[T]{
+		connections: connections,
+		target:      target,
+		waypoints:   make(map[string]uint64),
+		forbidden:   make(map[string]bool),
+		arith:       arith,
+		memo:        make(map[pathState]T),
+	}
+	for i, device := range waypoints {
+		pc.waypoints[device] = 1 << i
+		pc.allVisited |= 1 << i
+	}
+	for _, device := range forbidden {
+		pc.forbidden[device] = true
+	}
+
+	return pc
 }
 
-// numPaths computes the number of distinct paths from the start device to the target device
-// in a network of devices represented as a DAG.
-func numPaths(start, target string, connections map[string][]string) int {
+// count returns the number of valid paths from the device to the target, given the set of waypoints
+// visited on the way to the device.
+func (pc *pathCounter[T]) count(device string, visited uint64) (T, error) {
+	if pc.forbidden[device] {
+		return pc.arith.Zero(), nil
+	}
+	visited |= pc.waypoints[device]
+	if device == pc.target {
+		if visited == pc.allVisited {
+			return pc.arith.FromInt(1), nil
+		}
+		return pc.arith.Zero(), nil
+	}
+
 	// Cache results in memoization table
-	memo := make(map[string]int)
+	key := pathState{device, visited}
+	if val, exists := pc.memo[key]; exists {
+		return val, nil
+	}
 
-	var dp func(string) int
-	dp = func(node string) int {
-		if node == target {
-			return 1
+	count := pc.arith.Zero()
+	for _, neighbor := range pc.connections[device] {
+		neighborCount, err := pc.count(neighbor, visited)
+		if err != nil {
+			return count, err
 		}
-		if val, exists := memo[node]; exists {
-			return val
+		if count, err = pc.arith.Add(count, neighborCount); err != nil {
+			return count, err
 		}
+	}
+
+	pc.memo[key] = count
+	return count, nil
+}
 
-		count := 0
-		for _, neighbor := range connections[node] {
-			count += dp(neighbor)
+// enumeratePaths lists up to limit valid paths from the start device to the target, in the order
+// that a depth-first search following each device's connections in turn would find them. Branches
+// with no valid paths are skipped using the counter, so every branch searched yields a path.
+func enumeratePaths(pc *pathCounter[*big.Int], start string, limit int) [][]string {
+	var paths [][]string
+	var path []string
+
+	var dfs func(string, uint64)
+	dfs = func(device string, visited uint64) {
+		if len(paths) >= limit {
+			return
+		}
+		if count, _ := pc.count(device, visited); count.Sign() == 0 {
+			return // No valid paths continue through this device
 		}
 
-		memo[node] = count
-		return count
+		path = append(path, device)
+		visited |= pc.waypoints[device]
+		if device == pc.target {
+			paths = append(paths, slices.Clone(path))
+		} else {
+			for _, neighbor := range pc.connections[device] {
+				dfs(neighbor, visited)
+			}
+		}
+		path = path[:len(path)-1]
 	}
 
-	return dp(start)
+	dfs(start, 0)
+	return paths
 }
 
-// numPathsWithDACAndFFT computes the number of distinct paths from the start device to the target device
-// in a network of devices represented as a DAG. This version assumes that the "dac" and "fft" nodes
-// must be included in each valid path, in any order.
-func numPathsWithDACAndFFT(start, target string, connections map[string][]string) int {
-	// Cache results in memoization table
-	type state struct {
-		node       string
-		dacVisited bool
-		fftVisited bool
-	}
-	memo := make(map[state]int)
-
-	var dfs func(string, bool, bool) int
-	dfs = func(current string, dacVisited, fftVisited bool) int {
-		if current == target {
-			if dacVisited && fftVisited {
-				return 1
+// exportDeviceNetwork writes the device network to the file at path in Graphviz DOT format. Each device
+// is labelled with the number of paths from it to the target, ignoring waypoints and forbidden devices,
+// and the devices and connections on valid paths from the start device are highlighted, with waypoints
+// drawn as double circles and forbidden devices in red. Nothing is written if path is empty.
+func exportDeviceNetwork(path string, connections map[string][]string, start string,
+	validPaths *pathCounter[*big.Int]) error {
+	if path == "" {
+		return nil
+	}
+	if ext := filepath.Ext(path); ext != ".dot" {
+		return fmt.Errorf("unsupported export format %q (expected .dot)", ext)
+	}
+
+	// Gather every device, including those like the output device that connect to nothing
+	deviceSet := make(map[string]bool)
+	for device, neighbors := range connections {
+		deviceSet[device] = true
+		for _, neighbor := range neighbors {
+			deviceSet[neighbor] = true
+		}
+	}
+	devices := make([]string, 0, len(deviceSet))
+	for device := range deviceSet {
+		devices = append(devices, device)
+	}
+	sort.Strings(devices)
+
+	allPaths := newPathCounter(connections, validPaths.target, nil, nil, validPaths.arith)
+	onValidPath, validConnections := validPathNetwork(validPaths, start)
+
+	var sb strings.Builder
+	sb.WriteString("digraph devices {\n")
+	for _, device := range devices {
+		pathCount, err := allPaths.count(device, 0)
+		if err != nil {
+			return err
+		}
+
+		attrs := fmt.Sprintf("label=\"%s\\n%s paths to %s\"", device, pathCount, validPaths.target)
+		if onValidPath[device] {
+			attrs += ", style=filled, fillcolor=\"#bbdefb\""
+		}
+		if _, isWaypoint := validPaths.waypoints[device]; isWaypoint {
+			attrs += ", shape=doublecircle"
+		}
+		if validPaths.forbidden[device] {
+			attrs += ", color=red, fontcolor=red"
+		}
+		fmt.Fprintf(&sb, "  \"%s\" [%s];\n", device, attrs)
+	}
+	for _, device := range devices {
+		for _, neighbor := range connections[device] {
+			attrs := ""
+			if validConnections[[2]string{device, neighbor}] {
+				attrs = " [color=\"#1565c0\", penwidth=2]"
 			}
-			return 0
+			fmt.Fprintf(&sb, "  \"%s\" -> \"%s\"%s;\n", device, neighbor, attrs)
 		}
+	}
+	sb.WriteString("}\n")
+
+	return os.WriteFile(path, []byte(sb.String()), 0o644)
+}
 
-		if current == dacDevice {
-			dacVisited = true
+// validPathNetwork finds the devices and connections that lie on at least one valid path from the
+// start device to the target, by searching forwards from the start through the states that still
+// have valid paths to the target.
+func validPathNetwork(pc *pathCounter[*big.Int], start string) (map[string]bool, map[[2]string]bool) {
+	devices := make(map[string]bool)
+	connections := make(map[[2]string]bool)
+	seen := make(map[pathState]bool)
+
+	hasValidPaths := func(device string, visited uint64) bool {
+		count, _ := pc.count(device, visited)
+		return count.Sign() > 0
+	}
+
+	var dfs func(string, uint64)
+	dfs = func(device string, visited uint64) {
+		key := pathState{device, visited}
+		if seen[key] {
+			return
 		}
-		if current == fftDevice {
-			fftVisited = true
+		seen[key] = true
+		devices[device] = true
+
+		visited |= pc.waypoints[device]
+		if device == pc.target {
+			return
+		}
+		for _, neighbor := range pc.connections[device] {
+			if hasValidPaths(neighbor, visited) {
+				connections[[2]string{device, neighbor}] = true
+				dfs(neighbor, visited)
+			}
 		}
+	}
+
+	if hasValidPaths(start, 0) {
+		dfs(start, 0)
+	}
+	return devices, connections
+}
 
-		key := state{current, dacVisited, fftVisited}
-		if val, exists := memo[key]; exists {
-			return val
+// parseDeviceList parses a comma-separated list of device names, ignoring empty entries.
+func parseDeviceList(list string) []string {
+	var devices []string
+	for _, device := range strings.Split(list, ",") {
+		if device = strings.TrimSpace(device); device != "" {
+			devices = append(devices, device)
 		}
+	}
+	return devices
+}
 
-		count := 0
-		for _, neighbor := range connections[current] {
-			count += dfs(neighbor, dacVisited, fftVisited)
+// validateDeviceNetwork checks that the network can be searched for paths from the start device to the
+// target device: every device it connects to must have its own connections listed (apart from the output
+// device and the target, which may have none), the start device must be in the network, the target must
+// be reachable from it, and the network must be a DAG, since a cycle would give infinitely many paths.
+func validateDeviceNetwork(connections map[string][]string, start, target string) error {
+	devices := make([]string, 0, len(connections))
+	for device := range connections {
+		devices = append(devices, device)
+	}
+	sort.Strings(devices)
+
+	var unknown []string
+	for _, device := range devices {
+		for _, neighbor := range connections[device] {
+			if _, exists := connections[neighbor]; !exists && neighbor != target && neighbor != targetDevice {
+				unknown = append(unknown, fmt.Sprintf("%s -> %s", device, neighbor))
+			}
 		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("connections to unknown devices: %s", strings.Join(unknown, ", "))
+	}
 
-		memo[key] = count
-		return count
+	if _, exists := connections[start]; !exists && start != target {
+		return fmt.Errorf("start device %s is not in the network", start)
+	}
+	if cycle := findDeviceCycle(devices, connections); cycle != nil {
+		return fmt.Errorf("cycle %s", strings.Join(cycle, " -> "))
 	}
+	if !isDeviceReachable(start, target, connections) {
+		return fmt.Errorf("target device %s is not reachable from %s", target, start)
+	}
+
+	return nil
+}
+
+// findDeviceCycle searches the network from each device in turn for a cycle, returning the devices
+// around the first one found with the first device repeated at the end, or nil if the network is a DAG.
+func findDeviceCycle(devices []string, connections map[string][]string) []string {
+	const (
+		unvisited = iota
+		onPath    // Being searched from, so reaching it again closes a cycle
+		finished
+	)
+	states := make(map[string]int)
+	var path []string
+
+	var dfs func(string) []string
+	dfs = func(device string) []string {
+		states[device] = onPath
+		path = append(path, device)
+		for _, neighbor := range connections[device] {
+			switch states[neighbor] {
+			case onPath:
+				loop := slices.Clone(path[slices.Index(path, neighbor):])
+				return append(loop, neighbor)
+			case unvisited:
+				if cycle := dfs(neighbor); cycle != nil {
+					return cycle
+				}
+			}
+		}
+
+		path = path[:len(path)-1]
+		states[device] = finished
+		return nil
+	}
+
+	for _, device := range devices {
+		if states[device] == unvisited {
+			if cycle := dfs(device); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// isDeviceReachable reports whether there is a path from the start device to the target device.
+func isDeviceReachable(start, target string, connections map[string][]string) bool {
+	visited := map[string]bool{start: true}
+	stack := []string{start}
+	for len(stack) > 0 {
+		device := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if device == target {
+			return true
+		}
 
-	return dfs(start, false, false)
+		for _, neighbor := range connections[device] {
+			if !visited[neighbor] {
+				visited[neighbor] = true
+				stack = append(stack, neighbor)
+			}
+		}
+	}
+	return false
 }
 
 // parseDeviceConnections parses a list of device connection strings into a map
 // where each key is a device and the value is a list of devices it connects to.
-// The connections are unidirectional as specified in the input, resulting in a DAG.
-func parseDeviceConnections(input []string) map[string][]string {
+// The connections are unidirectional as specified in the input. Each device may
+// only be listed once.
+func parseDeviceConnections(input []string) (map[string][]string, error) {
 	connections := make(map[string][]string)
-	for _, line := range input {
-		parts := strings.Fields(line)
-		device := strings.TrimSuffix(parts[0], ":")
-		connections[device] = parts[1:]
+	listedOn := make(map[string]int) // Line number each device is listed on
+	for i, line := range input {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		device, outputs, found := strings.Cut(line, ":")
+		device = strings.TrimSpace(device)
+		if !found || device == "" {
+			return nil, fmt.Errorf("line %d: expected \"device: outputs\", got %q", i+1, line)
+		}
+		if previous, exists := listedOn[device]; exists {
+			return nil, fmt.Errorf("device %s is listed on both line %d and line %d", device, previous, i+1)
+		}
+
+		listedOn[device] = i + 1
+		connections[device] = strings.Fields(outputs)
 	}
 
-	return connections
+	return connections, nil
 }
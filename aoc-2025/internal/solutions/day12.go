@@ -1,32 +1,23 @@
 package solutions
 
 import (
+	"aoc-2025/internal/polyomino"
 	"aoc-2025/internal/registry"
+	"errors"
 	"fmt"
 	"slices"
 	"strconv"
 	"strings"
 )
 
-// FlipDirection represents the type of flip applied to a gift.
-type FlipDirection int
-
-const (
-	FlipNone FlipDirection = iota
-	FlipHorizontal
-	FlipVertical
-	FlipBoth
-)
-
-// Rotation represents the angle of clockwise rotation
-type Rotation int
-
-const (
-	Rotate0 Rotation = iota
-	Rotate90
-	Rotate180
-	Rotate270
-)
+// Placement records a single gift placed under a tree: which gift shape was used,
+// which of its precomputed orientations, and the top-left offset of that orientation.
+type Placement struct {
+	GiftIndex   int
+	Orientation int
+	Row         int
+	Col         int
+}
 
 // Tree represents an under-tree region with its dimensions and requested gift counts,
 // where GiftCounts[i] is the number of gifts of shape i requested.
@@ -36,24 +27,217 @@ type Tree struct {
 	GiftCounts []int
 }
 
+// Default amount of search work spent on each tree when minimising its bounding region, measured
+// in grid cells examined, since each step of the search scans the whole region. The puzzle's example
+// needs under 20 million to prove its minimum regions, which takes a couple of seconds.
+const defaultRegionSearchBudget = 50000000
+
 func init() {
 	registry.Register(12, 1, SolveDay12Part1)
+	registry.Register(12, 2, SolveDay12Part2)
+
+	registry.RegisterIntParam(12, 2, "budget", defaultRegionSearchBudget,
+		"search work (cells examined) to spend on each tree before reporting its minimum region as unknown")
 }
 
 func SolveDay12Part1(input []string) string {
-	gifts := parseGifts(input)
-	trees := parseTrees(input)
+	gifts, trees, err := parseGiftsAndTrees(input)
+	if err != nil {
+		return "invalid puzzle input: " + err.Error()
+	}
 	numTrees := numAccommodatingTrees(trees, gifts)
 	return fmt.Sprintf("The number of trees that can fit their requested gifts is %d", numTrees)
 }
 
+func SolveDay12Part2(input []string) string {
+	gifts, trees, err := parseGiftsAndTrees(input)
+	if err != nil {
+		return "invalid puzzle input: " + err.Error()
+	}
+	budget := registry.IntParam(12, 2, "budget")
+	if budget < 1 {
+		return fmt.Sprintf("invalid search budget: must be positive, got %d", budget)
+	}
+	giftOrientations := generateGiftOrientations(gifts)
+
+	var sb strings.Builder
+	totalUnused, numUnproven := 0, 0
+	for i, tree := range trees {
+		region := minBoundingRegion(tree, gifts, giftOrientations, budget)
+		if region.result == doesNotFit {
+			fmt.Fprintf(&sb, "Tree %d (%dx%d): gifts do not fit\n", i, tree.Width, tree.Height)
+			continue
+		}
+
+		unused := region.width*region.height - totalGiftArea(tree, gifts)
+		totalUnused += unused
+		if region.minimal {
+			fmt.Fprintf(&sb, "Tree %d (%dx%d): gifts fit in %dx%d, leaving %d cells unused\n",
+				i, tree.Width, tree.Height, region.width, region.height, unused)
+		} else {
+			numUnproven++
+			fmt.Fprintf(&sb, "Tree %d (%dx%d): gifts fit in %dx%d, leaving %d cells unused, "+
+				"but the minimum region is unknown\n", i, tree.Width, tree.Height, region.width, region.height, unused)
+		}
+		if registry.Verbose() {
+			sb.WriteString(renderPacking(Tree{region.width, region.height, tree.GiftCounts}, giftOrientations,
+				region.placements))
+		}
+	}
+
+	if numUnproven > 0 {
+		fmt.Fprintf(&sb, "The total minimum unused bounding area across all accommodating trees is unknown, "+
+			"as %d trees could not be decided within the search budget (at most %d across the trees known to fit)",
+			numUnproven, totalUnused)
+		return sb.String()
+	}
+	fmt.Fprintf(&sb, "The total minimum unused bounding area across all accommodating trees is %d", totalUnused)
+	return sb.String()
+}
+
+// boundingRegion is the smallest region found to hold a tree's gifts, and whether it is known to be
+// the smallest possible.
+type boundingRegion struct {
+	result     packResult // Whether the gifts fit under the tree at all
+	width      int
+	height     int
+	placements []Placement
+	minimal    bool
+}
+
+// minBoundingRegion finds the smallest-area region, no larger than the tree in either dimension, that
+// can accommodate all gifts requested for the tree. The tree itself is checked first, so that trees whose
+// gifts do not fit are rejected without trying smaller regions. Smaller regions are then tried in order
+// of area, skipping those too small for the gifts' total area or for some gift's bounding box, and
+// accepting the 3x3 block tiling where it fits. The backtracking searches of smaller regions share a
+// budget of search work; once it is spent, the smallest region found so far is returned without being
+// proven minimal.
+func minBoundingRegion(tree Tree, gifts []polyomino.Polyomino, giftOrientations [][]polyomino.Polyomino,
+	budget int) boundingRegion {
+	cellsNeeded := totalGiftArea(tree, gifts)
+	if cellsNeeded == 0 {
+		return boundingRegion{result: fits, minimal: true} // Nothing to place, so no region is needed
+	}
+
+	// Whether the gifts fit at all is always decided, however long it takes, as in part 1
+	placements, ok := packGiftsUnderTree(tree, gifts, giftOrientations, &packingStats{})
+	if !ok {
+		return boundingRegion{result: doesNotFit}
+	}
+	best := boundingRegion{result: fits, width: tree.Width, height: tree.Height, placements: placements}
+
+	// Collect every smaller candidate region that could hold the gifts, smallest area first
+	var candidates [][2]int
+	for height := 1; height <= tree.Height; height++ {
+		for width := 1; width <= tree.Width; width++ {
+			if width*height >= cellsNeeded && width*height < tree.Width*tree.Height &&
+				giftsFitDimensions(tree.GiftCounts, giftOrientations, width, height) {
+				candidates = append(candidates, [2]int{width, height})
+			}
+		}
+	}
+	slices.SortStableFunc(candidates, func(a, b [2]int) int {
+		return a[0]*a[1] - b[0]*b[1]
+	})
+
+	// Every candidate before the first that fits must be ruled out for that one to be minimal
+	minimal := true
+	for _, dims := range candidates {
+		region := Tree{Width: dims[0], Height: dims[1], GiftCounts: tree.GiftCounts}
+		if placements, ok := packGiftsInBlocks(region, giftOrientations); ok {
+			return boundingRegion{fits, dims[0], dims[1], placements, minimal}
+		}
+		if budget <= 0 {
+			minimal = false
+			continue
+		}
+
+		placements, result, spent := packGifts(region, gifts, giftOrientations, &packingStats{}, budget)
+		budget -= spent
+		switch result {
+		case fits:
+			return boundingRegion{fits, dims[0], dims[1], placements, minimal}
+		case undecided:
+			minimal = false
+		}
+	}
+
+	best.minimal = minimal
+	return best
+}
+
+// giftsFitDimensions reports whether every requested gift has some orientation whose bounding box
+// fits within a region of the given width and height.
+func giftsFitDimensions(giftCounts []int, giftOrientations [][]polyomino.Polyomino, width, height int) bool {
+	for giftIdx, count := range giftCounts {
+		if count == 0 {
+			continue
+		}
+
+		fitsRegion := slices.ContainsFunc(giftOrientations[giftIdx], func(orientation polyomino.Polyomino) bool {
+			giftHeight, giftWidth := orientation.BoundingBox()
+			return giftHeight <= height && giftWidth <= width
+		})
+		if !fitsRegion {
+			return false
+		}
+	}
+
+	return true
+}
+
+// totalGiftArea computes the number of cells covered by all gifts requested for the tree.
+func totalGiftArea(tree Tree, gifts []polyomino.Polyomino) int {
+	total := 0
+	for i, count := range tree.GiftCounts {
+		total += count * gifts[i].Area()
+	}
+
+	return total
+}
+
+// packingStats counts how many trees were decided by each rule of the packer, along with
+// how many search branches were cut by dead-region pruning.
+type packingStats struct {
+	areaRejected     int // Trees whose gifts need more cells than the region has
+	trivialFits      int // Trees where every gift gets its own 3x3 block
+	searchFits       int // Trees where the backtracking search found a packing
+	searchRejected   int // Trees where the backtracking search exhausted all options
+	deadRegionPrunes int // Search branches cut because isolated empty regions wasted too much space
+}
+
 // numAccommodatingTrees counts how many under-tree regions can accommodate their requested gifts.
-func numAccommodatingTrees(trees []Tree, gifts [][][2]int) int {
+// In verbose mode, the packing found for each accommodating tree is rendered to standard output,
+// followed by a summary of which rule decided each tree.
+func numAccommodatingTrees(trees []Tree, gifts []polyomino.Polyomino) int {
+	// Precompute all orientations for each gift
+	giftOrientations := generateGiftOrientations(gifts)
+
 	total := 0
-	for _, tree := range trees {
-		if canFitGiftsUnderTree(tree, gifts) {
+	var stats packingStats
+	for i, tree := range trees {
+		placements, ok := packGiftsUnderTree(tree, gifts, giftOrientations, &stats)
+		if ok {
 			total++
 		}
+
+		if registry.Verbose() {
+			fmt.Printf("Tree %d (%dx%d): ", i, tree.Width, tree.Height)
+			if !ok {
+				fmt.Println("gifts do not fit")
+				continue
+			}
+			fmt.Println("gifts fit")
+			fmt.Print(renderPacking(tree, giftOrientations, placements))
+		}
+	}
+
+	if registry.Verbose() {
+		fmt.Printf("Rejected by area check: %d\n", stats.areaRejected)
+		fmt.Printf("Accepted by 3x3 block tiling: %d\n", stats.trivialFits)
+		fmt.Printf("Accepted by search: %d\n", stats.searchFits)
+		fmt.Printf("Rejected by search: %d (%d branches pruned by dead regions)\n",
+			stats.searchRejected, stats.deadRegionPrunes)
 	}
 
 	return total
@@ -61,20 +245,46 @@ func numAccommodatingTrees(trees []Tree, gifts [][][2]int) int {
 
 // canFitGiftsUnderTree determines if the tree can accommodate all requested gifts
 // in any orientation without overlap.
-func canFitGiftsUnderTree(tree Tree, gifts [][][2]int) bool {
-	// Precompute all orientations for each gift
-	giftOrientations := make([][][][2]int, len(gifts))
-	for i, gift := range gifts {
-		giftOrientations[i] = generateAllOrientations(gift)
-	}
+func canFitGiftsUnderTree(tree Tree, gifts []polyomino.Polyomino) bool {
+	_, ok := packGiftsUnderTree(tree, gifts, generateGiftOrientations(gifts), &packingStats{})
+	return ok
+}
+
+// packResult is the outcome of searching for a packing of gifts under a tree.
+type packResult int
+
+const (
+	doesNotFit packResult = iota
+	fits
+	undecided // The search ran out of budget before finding a packing or ruling one out
+)
 
+// packGiftsUnderTree searches for an arrangement of all requested gifts under the tree
+// using the precomputed orientations of each gift. If one exists, it returns the placement
+// of every gift along with true. Cheap bound checks are tried before falling back to a
+// backtracking search, and the rule that decided the tree is recorded in stats.
+func packGiftsUnderTree(tree Tree, gifts []polyomino.Polyomino, giftOrientations [][]polyomino.Polyomino,
+	stats *packingStats) ([]Placement, bool) {
+	placements, result, _ := packGifts(tree, gifts, giftOrientations, stats, 0)
+	return placements, result == fits
+}
+
+// packGifts is packGiftsUnderTree with a limit on the work the backtracking search may do, counted
+// as the cells examined by each of its steps, where a budget of 0 means no limit. Along with the
+// placements and the result, which is undecided if the budget runs out, it returns the work done.
+func packGifts(tree Tree, gifts []polyomino.Polyomino, giftOrientations [][]polyomino.Polyomino,
+	stats *packingStats, budget int) ([]Placement, packResult, int) {
 	// Check if we even have enough total space under the tree for all gifts
-	totalCellsNeeded := 0
-	for i, count := range tree.GiftCounts {
-		totalCellsNeeded += count * len(gifts[i])
-	}
+	totalCellsNeeded := totalGiftArea(tree, gifts)
 	if totalCellsNeeded > tree.Width*tree.Height {
-		return false
+		stats.areaRejected++
+		return nil, doesNotFit, 0
+	}
+
+	// If every gift can be given its own 3x3 block, there is no need to search
+	if placements, ok := packGiftsInBlocks(tree, giftOrientations); ok {
+		stats.trivialFits++
+		return placements, fits, 0
 	}
 
 	// Create a grid to represent occupied spaces under the tree
@@ -83,58 +293,265 @@ func canFitGiftsUnderTree(tree Tree, gifts [][][2]int) bool {
 		occupied[i] = make([]bool, tree.Width)
 	}
 
-	memo := make(map[string]bool)
-	return tryPlaceAllGifts(occupied, giftOrientations, tree.GiftCounts, memo)
+	packer := &giftPacker{
+		occupied:        occupied,
+		allOrientations: giftOrientations,
+		giftSizes:       make([]int, len(gifts)),
+		counts:          slices.Clone(tree.GiftCounts), // Leave the tree's own counts untouched
+		memo:            make(map[string]bool),
+		stats:           stats,
+		budget:          budget,
+	}
+	for i, gift := range gifts {
+		packer.giftSizes[i] = gift.Area()
+	}
+
+	// Slack is the number of cells that may be left empty once every gift is placed
+	if !packer.search(tree.Width*tree.Height - totalCellsNeeded) {
+		if packer.outOfBudget {
+			return nil, undecided, packer.work
+		}
+		stats.searchRejected++
+		return nil, doesNotFit, packer.work
+	}
+
+	stats.searchFits++
+	return packer.placements, fits, packer.work
 }
 
-// tryPlaceAllGifts attempts to place all gifts trying different orientations during placement.
-func tryPlaceAllGifts(occupied [][]bool, allOrientations [][][][2]int, counts []int, memo map[string]bool) bool {
-	if !slices.ContainsFunc(counts, func(x int) bool { return x != 0 }) {
+// packGiftsInBlocks tries the trivial packing where the region is divided into 3x3 blocks and
+// each gift is placed alone in its own block. This succeeds when every requested gift has an
+// orientation fitting in 3x3 and there are at least as many blocks as gifts.
+func packGiftsInBlocks(tree Tree, allOrientations [][]polyomino.Polyomino) ([]Placement, bool) {
+	blocksPerRow := tree.Width / 3
+	numBlocks := blocksPerRow * (tree.Height / 3)
+
+	var placements []Placement
+	for giftIdx, count := range tree.GiftCounts {
+		if count == 0 {
+			continue
+		}
+
+		orientationIdx := slices.IndexFunc(allOrientations[giftIdx], func(orientation polyomino.Polyomino) bool {
+			height, width := orientation.BoundingBox()
+			return height <= 3 && width <= 3
+		})
+		if orientationIdx < 0 {
+			return nil, false // This gift needs more room than a single block
+		}
+
+		for range count {
+			block := len(placements)
+			if block >= numBlocks {
+				return nil, false // Ran out of blocks
+			}
+			row, col := 3*(block/blocksPerRow), 3*(block%blocksPerRow)
+			placements = append(placements, Placement{giftIdx, orientationIdx, row, col})
+		}
+	}
+
+	return placements, true
+}
+
+// generateGiftOrientations precomputes all unique orientations of every gift.
+func generateGiftOrientations(gifts []polyomino.Polyomino) [][]polyomino.Polyomino {
+	giftOrientations := make([][]polyomino.Polyomino, len(gifts))
+	for i, gift := range gifts {
+		giftOrientations[i] = gift.Orientations()
+	}
+
+	return giftOrientations
+}
+
+// giftPacker holds the state of the backtracking search for a packing of gifts under a tree.
+type giftPacker struct {
+	occupied        [][]bool
+	allOrientations [][]polyomino.Polyomino
+	giftSizes       []int
+	counts          []int
+	memo            map[string]bool
+	placements      []Placement
+	stats           *packingStats
+	budget          int  // Maximum search work in cells examined, or 0 for no limit
+	work            int  // Search work done so far
+	outOfBudget     bool // Whether the search gave up after using its whole budget
+}
+
+// search attempts to place all remaining gifts, filling the grid in row-major order. The first
+// empty cell must either be covered by some gift or deliberately left empty, which is only
+// allowed while there is slack to spare. Successful placements are recorded in order, and
+// removed again when backtracking.
+func (p *giftPacker) search(slack int) bool {
+	if p.budget > 0 && p.work >= p.budget {
+		p.outOfBudget = true
+		return false
+	}
+	p.work += len(p.occupied) * len(p.occupied[0])
+
+	if !slices.ContainsFunc(p.counts, func(x int) bool { return x != 0 }) {
 		return true // All gifts successfully placed
 	}
 
-	// Check memoization table
-	key := gridStateKey(occupied, counts)
-	if result, exists := memo[key]; exists {
+	// Check memoization table (the slack is implied by the grid and counts, so is not part of the key).
+	// Once the budget runs out, failures are no longer proven, but the search is abandoned anyway.
+	key := gridStateKey(p.occupied, p.counts)
+	if result, exists := p.memo[key]; exists {
 		return result
 	}
 
-	// Try to place the next gift from any gift that still has remaining quantity
-	for giftIdx, count := range counts {
-		if count > 0 {
-			// Try every orientation of this gift
-			for _, orientation := range allOrientations[giftIdx] {
-				// Try every possible position under the tree
-				for startRow := range occupied {
-					for startCol := range occupied[0] {
-						if canPlaceGiftAt(occupied, orientation, startRow, startCol) {
-							// Place the gift
-							placeGiftAt(occupied, orientation, startRow, startCol, true)
-							counts[giftIdx]--
-
-							// Recursively try to place remaining gifts
-							if tryPlaceAllGifts(occupied, allOrientations, counts, memo) {
-								memo[key] = true
-								return true
-							}
-
-							// Backtrack
-							counts[giftIdx]++
-							placeGiftAt(occupied, orientation, startRow, startCol, false)
-						}
+	row, col, found := firstEmptyCell(p.occupied)
+	if !found || p.wastedCells() > slack {
+		if found {
+			p.stats.deadRegionPrunes++
+		}
+		p.memo[key] = false
+		return false
+	}
+
+	// Try to cover the first empty cell with every orientation of every remaining gift
+	for giftIdx, count := range p.counts {
+		if count == 0 {
+			continue
+		}
+
+		for orientationIdx, orientation := range p.allOrientations[giftIdx] {
+			// Orientations are normalized, so their first cell is the top-most, left-most one
+			anchor := orientation[0]
+			startRow, startCol := row-anchor[0], col-anchor[1]
+			if !canPlaceGiftAt(p.occupied, orientation, startRow, startCol) {
+				continue
+			}
+
+			// Place the gift
+			placeGiftAt(p.occupied, orientation, startRow, startCol, true)
+			p.counts[giftIdx]--
+			p.placements = append(p.placements, Placement{giftIdx, orientationIdx, startRow, startCol})
+
+			// Recursively try to place remaining gifts
+			if p.search(slack) {
+				return true
+			}
+
+			// Backtrack
+			p.placements = p.placements[:len(p.placements)-1]
+			p.counts[giftIdx]++
+			placeGiftAt(p.occupied, orientation, startRow, startCol, false)
+		}
+	}
+
+	// Otherwise, leave the cell empty if we can afford to
+	if slack > 0 {
+		p.occupied[row][col] = true
+		if p.search(slack - 1) {
+			return true
+		}
+		p.occupied[row][col] = false
+	}
+
+	p.memo[key] = false
+	return false
+}
+
+// wastedCells counts the empty cells that can never be covered because they belong to an
+// isolated empty region smaller than the smallest gift still waiting to be placed.
+func (p *giftPacker) wastedCells() int {
+	smallestGift := -1
+	for giftIdx, count := range p.counts {
+		if count > 0 && (smallestGift < 0 || p.giftSizes[giftIdx] < smallestGift) {
+			smallestGift = p.giftSizes[giftIdx]
+		}
+	}
+
+	rows, cols := len(p.occupied), len(p.occupied[0])
+	visited := make([][]bool, rows)
+	for i := range visited {
+		visited[i] = make([]bool, cols)
+	}
+
+	// Flood fill each empty region to measure its size
+	wasted := 0
+	var stack [][2]int
+	for r := range rows {
+		for c := range cols {
+			if p.occupied[r][c] || visited[r][c] {
+				continue
+			}
+
+			regionSize := 0
+			visited[r][c] = true
+			stack = append(stack[:0], [2]int{r, c})
+			for len(stack) > 0 {
+				cell := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				regionSize++
+
+				for _, dir := range [4][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+					nr, nc := cell[0]+dir[0], cell[1]+dir[1]
+					if nr >= 0 && nr < rows && nc >= 0 && nc < cols && !p.occupied[nr][nc] && !visited[nr][nc] {
+						visited[nr][nc] = true
+						stack = append(stack, [2]int{nr, nc})
 					}
 				}
 			}
 
-			// If we have tried all orientations and positions for this gift and none worked,
-			// there is no point in continuing with the other gifts
-			memo[key] = false
-			return false
+			if regionSize < smallestGift {
+				wasted += regionSize
+			}
 		}
 	}
 
-	memo[key] = false
-	return false
+	return wasted
+}
+
+// firstEmptyCell finds the first unoccupied cell of the grid in row-major order.
+func firstEmptyCell(occupied [][]bool) (int, int, bool) {
+	for row := range occupied {
+		for col := range occupied[row] {
+			if !occupied[row][col] {
+				return row, col, true
+			}
+		}
+	}
+
+	return 0, 0, false
+}
+
+// renderPacking draws the region under the tree with every placed gift labelled by its own letter,
+// coloured according to its gift shape using ANSI escape codes. Empty cells are drawn as '.'.
+func renderPacking(tree Tree, allOrientations [][]polyomino.Polyomino, placements []Placement) string {
+	const labels = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	const ansiReset = "\x1b[0m"
+
+	// Record which placement covers each cell, if any
+	owner := make([][]int, tree.Height)
+	for row := range owner {
+		owner[row] = make([]int, tree.Width)
+		for col := range owner[row] {
+			owner[row][col] = -1
+		}
+	}
+	for i, p := range placements {
+		for _, coord := range allOrientations[p.GiftIndex][p.Orientation] {
+			owner[p.Row+coord[0]][p.Col+coord[1]] = i
+		}
+	}
+
+	var sb strings.Builder
+	for _, row := range owner {
+		for _, placementIdx := range row {
+			if placementIdx < 0 {
+				sb.WriteByte('.')
+				continue
+			}
+
+			// Cycle through the six standard foreground colours (31-36) by gift shape
+			colour := 31 + placements[placementIdx].GiftIndex%6
+			fmt.Fprintf(&sb, "\x1b[%dm%c%s", colour, labels[placementIdx%len(labels)], ansiReset)
+		}
+		sb.WriteByte('\n')
+	}
+
+	return sb.String()
 }
 
 // gridStateKey creates a unique key for memoization
@@ -189,152 +606,101 @@ func placeGiftAt(occupied [][]bool, gift [][2]int, startRow, startCol int, place
 	}
 }
 
-// generateAllOrientations generates all unique orientations of a given gift.
-func generateAllOrientations(gift [][2]int) [][][2]int {
-	var orientations [][][2]int
-	flipDirections := []FlipDirection{FlipNone, FlipHorizontal, FlipVertical, FlipBoth}
-	rotations := []Rotation{Rotate0, Rotate90, Rotate180, Rotate270}
-
-	seen := make(map[string]bool)
-	for _, flip := range flipDirections {
-		flippedGift := flipGift(gift, flip)
-		for _, rotation := range rotations {
-			rotatedGift := rotateGift(flippedGift, rotation)
-			normalizedGift := normalizeGift(rotatedGift)
-
-			// Create a unique key for each gift orientation to avoid duplicates
-			var keyParts []string
-			for _, coord := range normalizedGift {
-				keyParts = append(keyParts, fmt.Sprintf("%d,%d", coord[0], coord[1]))
-			}
-			key := strings.Join(keyParts, ";")
-			if !seen[key] {
-				seen[key] = true
-				orientations = append(orientations, normalizedGift)
-			}
-		}
-	}
-
-	return orientations
-}
-
-// normalizeGift shifts all gift coordinates so the minimum row and column are both 0.
-func normalizeGift(gift [][2]int) [][2]int {
-	minRow, minCol := gift[0][0], gift[0][1]
-	for _, coord := range gift {
-		minRow = min(minRow, coord[0])
-		minCol = min(minCol, coord[1])
-	}
-
-	normalized := make([][2]int, len(gift))
-	for i, coord := range gift {
-		normalized[i] = [2]int{coord[0] - minRow, coord[1] - minCol}
-	}
-
-	return normalized
-}
+// parseGiftsAndTrees parses the puzzle input, which consists of a section of gift shapes followed
+// by a section of trees. Each gift starts with an "<index>:" header line followed by its shape drawn
+// with '#' and '.', and gifts are separated by blank lines. Each tree is a single line formatted as
+// "<width>x<height>: <count> <count> ...", with exactly one requested count per gift shape.
+func parseGiftsAndTrees(input []string) ([]polyomino.Polyomino, []Tree, error) {
+	var gifts []polyomino.Polyomino
+	var trees []Tree
 
-// flipGift flips the gift coordinates based on the specified flip direction.
-func flipGift(gift [][2]int, direction FlipDirection) [][2]int {
-	flipped := make([][2]int, len(gift))
-	for i, coord := range gift {
-		row, col := coord[0], coord[1]
-		switch direction {
-		case FlipNone:
-			flipped[i] = [2]int{row, col}
-		case FlipHorizontal:
-			flipped[i] = [2]int{row, -col}
-		case FlipVertical:
-			flipped[i] = [2]int{-row, col}
-		case FlipBoth:
-			flipped[i] = [2]int{-row, -col}
+	for i := 0; i < len(input); i++ {
+		line := strings.TrimSpace(input[i])
+		if line == "" {
+			continue // Blank lines only separate sections
 		}
-	}
 
-	return flipped
-}
-
-// rotateGift rotates the gift coordinates based on the specified rotation angle.
-func rotateGift(gift [][2]int, rotation Rotation) [][2]int {
-	rotated := make([][2]int, len(gift))
-	for i, coord := range gift {
-		row, col := coord[0], coord[1]
-		switch rotation {
-		case Rotate0:
-			rotated[i] = [2]int{row, col}
-		case Rotate90:
-			rotated[i] = [2]int{col, -row}
-		case Rotate180:
-			rotated[i] = [2]int{-row, -col}
-		case Rotate270:
-			rotated[i] = [2]int{-col, row}
+		header, rest, found := strings.Cut(line, ":")
+		if !found {
+			return nil, nil, fmt.Errorf("line %d: expected a gift header or tree, got %q", i+1, line)
 		}
-	}
 
-	return rotated
-}
-
-// parseGifts parses the input lines to extract gift coordinates from their respective
-// graphical representations within a grid.
-func parseGifts(input []string) [][][2]int {
-	var gifts [][][2]int
-	var currentGift [][2]int
-	row := 0
-
-	for _, line := range input {
-		if line == "" {
-			// End of current gift
-			gifts = append(gifts, currentGift)
-			row = 0
-			continue
-		}
-		if line[len(line)-1] == ':' {
-			// New gift header
-			currentGift = [][2]int{}
-			row = 0
+		giftIdx, err := strconv.Atoi(header)
+		if err != nil {
+			// Anything that is not a gift header must be a tree
+			tree, err := parseTree(header, rest, len(gifts))
+			if err != nil {
+				return nil, nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			trees = append(trees, tree)
 			continue
 		}
-		if strings.Contains(line, "x") {
-			break // Start of tree inputs, stop parsing gifts
+
+		switch {
+		case len(trees) > 0:
+			return nil, nil, fmt.Errorf("line %d: gift %d appears after the tree section", i+1, giftIdx)
+		case strings.TrimSpace(rest) != "":
+			return nil, nil, fmt.Errorf("line %d: unexpected text after gift header: %q", i+1, rest)
+		case giftIdx != len(gifts):
+			return nil, nil, fmt.Errorf("line %d: expected gift %d, got gift %d", i+1, len(gifts), giftIdx)
 		}
 
-		for col, char := range line {
-			if char == '#' {
-				currentGift = append(currentGift, [2]int{row, col})
-			}
+		// The gift's shape runs until the next blank line or the end of the input
+		shapeStart := i + 1
+		for i+1 < len(input) && strings.TrimSpace(input[i+1]) != "" {
+			i++
+		}
+		gift, err := polyomino.Parse(input[shapeStart : i+1])
+		if err != nil {
+			return nil, nil, fmt.Errorf("gift %d (shape starting on line %d): %w", giftIdx, shapeStart+1, err)
 		}
-		row++
+		gifts = append(gifts, gift)
+	}
+
+	if len(gifts) == 0 {
+		return nil, nil, errors.New("no gift shapes found")
+	}
+	if len(trees) == 0 {
+		return nil, nil, errors.New("no trees found")
 	}
 
-	return gifts
+	return gifts, trees, nil
 }
 
-// parseTrees parses the input lines to extract under-tree region dimensions
-// and their corresponding requested gift counts.
-func parseTrees(input []string) []Tree {
-	var trees []Tree
+// parseTree parses a single tree from its "<width>x<height>" dimensions and its
+// whitespace-separated gift counts, which must list one count for each gift shape.
+func parseTree(dimensions, counts string, numGifts int) (Tree, error) {
+	widthStr, heightStr, found := strings.Cut(dimensions, "x")
+	if !found {
+		return Tree{}, fmt.Errorf("invalid tree dimensions %q: expected <width>x<height>", dimensions)
+	}
+	width, err := strconv.Atoi(widthStr)
+	if err != nil || width <= 0 {
+		return Tree{}, fmt.Errorf("invalid tree width %q", widthStr)
+	}
+	height, err := strconv.Atoi(heightStr)
+	if err != nil || height <= 0 {
+		return Tree{}, fmt.Errorf("invalid tree height %q", heightStr)
+	}
 
-	for _, line := range input {
-		if strings.Contains(line, "x") && strings.Contains(line, ":") {
-			parts := strings.Split(line, ":")
-			dimParts := strings.Split(parts[0], "x")
-			width, _ := strconv.Atoi(dimParts[0])
-			height, _ := strconv.Atoi(dimParts[1])
-
-			var counts []int
-			countParts := strings.Fields(parts[1])
-			for _, countStr := range countParts {
-				count, _ := strconv.Atoi(countStr)
-				counts = append(counts, count)
-			}
+	countParts := strings.Fields(counts)
+	if len(countParts) != numGifts {
+		return Tree{}, fmt.Errorf("tree %s lists %d gift counts, but there are %d gift shapes",
+			dimensions, len(countParts), numGifts)
+	}
 
-			trees = append(trees, Tree{
-				Width:      width,
-				Height:     height,
-				GiftCounts: counts,
-			})
+	giftCounts := make([]int, numGifts)
+	for i, countStr := range countParts {
+		count, err := strconv.Atoi(countStr)
+		if err != nil || count < 0 {
+			return Tree{}, fmt.Errorf("tree %s has invalid count %q for gift %d", dimensions, countStr, i)
 		}
+		giftCounts[i] = count
 	}
 
-	return trees
+	return Tree{
+		Width:      width,
+		Height:     height,
+		GiftCounts: giftCounts,
+	}, nil
 }
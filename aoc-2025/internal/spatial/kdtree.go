@@ -0,0 +1,157 @@
+package spatial
+
+import (
+	"slices"
+)
+
+// noLabel marks a subtree whose points do not all share the same label.
+const noLabel = -1
+
+// KDTree is a static k-d tree over 3D integer points supporting nearest-neighbour and
+// radius queries by squared Euclidean distance. Lower-dimensional points can be stored
+// by leaving their unused coordinates at zero.
+//
+// The tree is stored implicitly: the subtree covering order[lo:hi] is rooted at the
+// median index (lo+hi)/2 and splits on axis depth%3.
+type KDTree struct {
+	points      [][3]int
+	order       []int // Point indices arranged in tree order
+	labels      []int // Label of each point, indexed by point index
+	rangeLabels []int // Label shared by every point in the subtree rooted at each tree position
+}
+
+// NewKDTree builds a k-d tree over the given points. Query results refer to points
+// by their index in this slice.
+func NewKDTree(points [][3]int) *KDTree {
+	t := &KDTree{
+		points:      points,
+		order:       make([]int, len(points)),
+		labels:      make([]int, len(points)),
+		rangeLabels: make([]int, len(points)),
+	}
+	for i := range t.order {
+		t.order[i] = i
+	}
+	t.build(0, len(points), 0)
+
+	return t
+}
+
+// build arranges order[lo:hi] so the median point along the current axis sits at the
+// middle, with smaller coordinates to its left and larger ones to its right.
+func (t *KDTree) build(lo, hi, depth int) {
+	if hi-lo <= 1 {
+		return
+	}
+
+	axis := depth % 3
+	slices.SortFunc(t.order[lo:hi], func(a, b int) int {
+		return t.points[a][axis] - t.points[b][axis]
+	})
+
+	mid := (lo + hi) / 2
+	t.build(lo, mid, depth+1)
+	t.build(mid+1, hi, depth+1)
+}
+
+// SetLabels assigns a label to every point, indexed like the points passed to NewKDTree.
+// Labels must be non-negative. Subtrees whose points all share a label are recorded so that
+// NearestWithDifferentLabel can skip them entirely.
+func (t *KDTree) SetLabels(labels []int) {
+	copy(t.labels, labels)
+	t.labelRange(0, len(t.order))
+}
+
+// labelRange computes the shared label of the subtree covering order[lo:hi].
+func (t *KDTree) labelRange(lo, hi int) int {
+	if lo >= hi {
+		return noLabel
+	}
+
+	mid := (lo + hi) / 2
+	label := t.labels[t.order[mid]]
+	left, right := t.labelRange(lo, mid), t.labelRange(mid+1, hi)
+	if (mid > lo && left != label) || (hi > mid+1 && right != label) {
+		label = noLabel
+	}
+
+	t.rangeLabels[mid] = label
+	return label
+}
+
+// NearestWithDifferentLabel finds the point closest to the query whose label differs from the
+// given one, breaking distance ties in favour of the lower point index. It returns the point
+// index and squared distance, or false if every point carries the given label.
+func (t *KDTree) NearestWithDifferentLabel(query [3]int, label int) (int, int, bool) {
+	best, bestDist := -1, 0
+	t.nearest(0, len(t.order), 0, query, label, &best, &bestDist)
+	return best, bestDist, best >= 0
+}
+
+func (t *KDTree) nearest(lo, hi, depth int, query [3]int, label int, best, bestDist *int) {
+	if lo >= hi {
+		return
+	}
+
+	mid := (lo + hi) / 2
+	if t.rangeLabels[mid] == label {
+		return // Every point in this subtree carries the excluded label
+	}
+
+	idx := t.order[mid]
+	if t.labels[idx] != label {
+		dist := squaredDistance(query, t.points[idx])
+		if *best < 0 || dist < *bestDist || (dist == *bestDist && idx < *best) {
+			*best, *bestDist = idx, dist
+		}
+	}
+
+	// Search the side of the splitting plane containing the query first, then the other
+	// side only if the plane is no farther away than the best candidate so far
+	axis := depth % 3
+	diff := query[axis] - t.points[idx][axis]
+	near, far := [2]int{lo, mid}, [2]int{mid + 1, hi}
+	if diff > 0 {
+		near, far = far, near
+	}
+
+	t.nearest(near[0], near[1], depth+1, query, label, best, bestDist)
+	if *best < 0 || diff*diff <= *bestDist {
+		t.nearest(far[0], far[1], depth+1, query, label, best, bestDist)
+	}
+}
+
+// WithinRadius calls visit for every point whose squared distance from the query is at most
+// radiusSquared, passing the point index and its squared distance. Points are visited in no
+// particular order.
+func (t *KDTree) WithinRadius(query [3]int, radiusSquared int, visit func(idx, distSquared int)) {
+	t.withinRadius(0, len(t.order), 0, query, radiusSquared, visit)
+}
+
+func (t *KDTree) withinRadius(lo, hi, depth int, query [3]int, radiusSquared int, visit func(int, int)) {
+	if lo >= hi {
+		return
+	}
+
+	mid := (lo + hi) / 2
+	idx := t.order[mid]
+	if dist := squaredDistance(query, t.points[idx]); dist <= radiusSquared {
+		visit(idx, dist)
+	}
+
+	// Only descend into a side of the splitting plane if the query ball reaches it
+	axis := depth % 3
+	diff := query[axis] - t.points[idx][axis]
+	if diff <= 0 || diff*diff <= radiusSquared {
+		t.withinRadius(lo, mid, depth+1, query, radiusSquared, visit)
+	}
+	if diff >= 0 || diff*diff <= radiusSquared {
+		t.withinRadius(mid+1, hi, depth+1, query, radiusSquared, visit)
+	}
+}
+
+// squaredDistance computes the squared Euclidean distance between two points.
+func squaredDistance(a, b [3]int) int {
+	dx, dy, dz := a[0]-b[0], a[1]-b[1], a[2]-b[2]
+	return dx*dx + dy*dy + dz*dz
+}
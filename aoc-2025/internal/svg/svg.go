@@ -0,0 +1,163 @@
+package svg
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"os"
+	"strings"
+)
+
+// outputWidth is the width in pixels of the rendered image. The height follows the aspect ratio.
+const outputWidth = 1000
+
+// Style describes how a shape is painted. Empty colours are not painted, and stroke widths are
+// measured in output pixels regardless of the scale of the drawing's coordinates.
+type Style struct {
+	Fill        string
+	Stroke      string
+	StrokeWidth float64
+	Opacity     float64 // Treated as fully opaque when zero
+	HideAt      float64 // Seconds into the animation at which the shape disappears; never hidden when zero
+}
+
+// attributes formats the style as SVG presentation attributes.
+func (s Style) attributes() string {
+	fill, stroke := s.Fill, s.Stroke
+	if fill == "" {
+		fill = "none"
+	}
+	if stroke == "" {
+		stroke = "none"
+	}
+
+	attrs := fmt.Sprintf(`fill="%s" stroke="%s" stroke-width="%g" vector-effect="non-scaling-stroke"`,
+		html.EscapeString(fill), html.EscapeString(stroke), s.StrokeWidth)
+	if s.Opacity > 0 {
+		attrs += fmt.Sprintf(` opacity="%g"`, s.Opacity)
+	}
+	return attrs
+}
+
+// element formats a shape with the given tag and geometry attributes in the style, animating
+// it out of view if the style has a hide time.
+func (s Style) element(tag, geometry string) string {
+	if s.HideAt <= 0 {
+		return fmt.Sprintf(`<%s %s %s/>`, tag, geometry, s.attributes())
+	}
+	return fmt.Sprintf(`<%s %s %s><set attributeName="visibility" to="hidden" begin="%gs" fill="freeze"/></%s>`,
+		tag, geometry, s.attributes(), s.HideAt, tag)
+}
+
+// Document accumulates shapes drawn in the caller's own coordinate system, with y pointing down,
+// and writes them out as a standalone SVG image whose view box fits every shape.
+type Document struct {
+	elements               []string
+	minX, minY, maxX, maxY float64
+}
+
+// New creates an empty document.
+func New() *Document {
+	return &Document{
+		minX: math.Inf(1), minY: math.Inf(1),
+		maxX: math.Inf(-1), maxY: math.Inf(-1),
+	}
+}
+
+// include grows the document's bounds to cover the point.
+func (d *Document) include(x, y float64) {
+	d.minX, d.maxX = min(d.minX, x), max(d.maxX, x)
+	d.minY, d.maxY = min(d.minY, y), max(d.maxY, y)
+}
+
+// Rect draws an axis-aligned rectangle with its top-left corner at (x, y).
+func (d *Document) Rect(x, y, width, height float64, style Style) {
+	d.include(x, y)
+	d.include(x+width, y+height)
+	d.elements = append(d.elements, style.element("rect",
+		fmt.Sprintf(`x="%g" y="%g" width="%g" height="%g"`, x, y, width, height)))
+}
+
+// Line draws a straight line segment between two points.
+func (d *Document) Line(x1, y1, x2, y2 float64, style Style) {
+	d.include(x1, y1)
+	d.include(x2, y2)
+	d.elements = append(d.elements, style.element("line",
+		fmt.Sprintf(`x1="%g" y1="%g" x2="%g" y2="%g"`, x1, y1, x2, y2)))
+}
+
+// Polygon draws a closed shape through the given [x, y] points.
+func (d *Document) Polygon(points [][2]float64, style Style) {
+	d.elements = append(d.elements, style.element("polygon", fmt.Sprintf(`points="%s"`, d.formatPoints(points))))
+}
+
+// Polyline draws an open path through the given [x, y] points.
+func (d *Document) Polyline(points [][2]float64, style Style) {
+	d.elements = append(d.elements, style.element("polyline", fmt.Sprintf(`points="%s"`, d.formatPoints(points))))
+}
+
+// formatPoints formats a list of points for a points attribute, growing the bounds to cover them.
+func (d *Document) formatPoints(points [][2]float64) string {
+	var sb strings.Builder
+	for i, p := range points {
+		d.include(p[0], p[1])
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		fmt.Fprintf(&sb, "%g,%g", p[0], p[1])
+	}
+	return sb.String()
+}
+
+// Circle draws a circle centred at (cx, cy).
+func (d *Document) Circle(cx, cy, radius float64, style Style) {
+	d.include(cx-radius, cy-radius)
+	d.include(cx+radius, cy+radius)
+	d.elements = append(d.elements, style.element("circle",
+		fmt.Sprintf(`cx="%g" cy="%g" r="%g"`, cx, cy, radius)))
+}
+
+// Text draws a label with its baseline starting at (x, y), with the font size given in the
+// drawing's coordinates.
+func (d *Document) Text(x, y float64, text string, size float64, style Style) {
+	d.include(x, y)
+	d.elements = append(d.elements, fmt.Sprintf(
+		`<text x="%g" y="%g" font-size="%g" font-family="monospace" %s>%s</text>`,
+		x, y, size, style.attributes(), html.EscapeString(text)))
+}
+
+// Extent returns the larger of the drawing's width and height so far, which is useful for
+// sizing markers relative to the whole drawing. It is zero for an empty drawing.
+func (d *Document) Extent() float64 {
+	if len(d.elements) == 0 {
+		return 0
+	}
+	return max(d.maxX-d.minX, d.maxY-d.minY)
+}
+
+// String renders the document as SVG markup, with a small margin around the drawing.
+func (d *Document) String() string {
+	minX, minY, width, height := 0.0, 0.0, 1.0, 1.0
+	if len(d.elements) > 0 {
+		margin := math.Ceil(max(d.Extent()*0.02, 1))
+		minX, minY = d.minX-margin, d.minY-margin
+		width, height = d.maxX-d.minX+2*margin, d.maxY-d.minY+2*margin
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="%g %g %g %g">`+"\n",
+		outputWidth, int(math.Ceil(outputWidth*height/width)), minX, minY, width, height)
+	for _, element := range d.elements {
+		sb.WriteString("  ")
+		sb.WriteString(element)
+		sb.WriteByte('\n')
+	}
+	sb.WriteString("</svg>\n")
+
+	return sb.String()
+}
+
+// WriteFile writes the rendered document to the file at path.
+func (d *Document) WriteFile(path string) error {
+	return os.WriteFile(path, []byte(d.String()), 0o644)
+}
@@ -7,8 +7,8 @@ import (
 	"os"
 )
 
-// LoadInput reads the input file and returns a slice of strings (one per line)
-func LoadInput(day int, override string) []string {
+// OpenInput opens the input file for reading; the caller must close it
+func OpenInput(day int, override string) *os.File {
 	path := override
 	if path == "" {
 		path = fmt.Sprintf("inputs/day%02d.txt", day)
@@ -17,6 +17,12 @@ func LoadInput(day int, override string) []string {
 	if err != nil {
 		log.Fatalf("failed to load input: %v", err)
 	}
+	return f
+}
+
+// LoadInput reads the input file and returns a slice of strings (one per line)
+func LoadInput(day int, override string) []string {
+	f := OpenInput(day, override)
 	defer f.Close()
 
 	var lines []string